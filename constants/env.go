@@ -2,8 +2,10 @@ package constants
 
 import (
 	"os"
+	"time"
 
 	"github.com/caarlos0/env"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
 )
 
 // Env is environment variables
@@ -12,6 +14,7 @@ var Env envVars
 func init() {
 	err := env.Parse(&Env)
 	if err != nil {
+		logger.Err("Env", "Error while parsing environment variables", err, nil)
 		os.Exit(1)
 	}
 }
@@ -20,6 +23,60 @@ type envVars struct {
 	Environment string `env:"ENV" envDefault:"dev"`
 	Port        string `env:"PORT" envDefault:"3050"`
 
-	// Redis config
-	RedisURL string `env:"REDIS_URL" envDefault:"localhost:6379"`
+	// Redis config, mode is standalone, sentinel or cluster and all of them can use tls
+	// standalone redis is at REDIS_URL, which is host:port in dev environment and redis url otherwise
+	// sentinel and cluster modes have comma separated host:port of the sentinels or of the cluster nodes in REDIS_ADDRS
+	// zero pool size means default pool size of the client i.e. 10 connections per cpu
+	RedisURL           string        `env:"REDIS_URL" envDefault:"localhost:6379"`
+	RedisMode          string        `env:"REDIS_MODE" envDefault:"standalone"`
+	RedisAddrs         []string      `env:"REDIS_ADDRS" envSeparator:","`
+	RedisMasterName    string        `env:"REDIS_MASTER_NAME"`
+	RedisPassword      string        `env:"REDIS_PASSWORD"`
+	RedisDB            int           `env:"REDIS_DB" envDefault:"0"`
+	RedisTLS           bool          `env:"REDIS_TLS" envDefault:"false"`
+	RedisTLSCAFile     string        `env:"REDIS_TLS_CA_FILE"`
+	RedisTLSServerName string        `env:"REDIS_TLS_SERVER_NAME"`
+	RedisPoolSize      int           `env:"REDIS_POOL_SIZE" envDefault:"0"`
+	RedisDialTimeout   time.Duration `env:"REDIS_DIAL_TIMEOUT" envDefault:"1s"`
+	RedisReadTimeout   time.Duration `env:"REDIS_READ_TIMEOUT" envDefault:"1s"`
+	RedisWriteTimeout  time.Duration `env:"REDIS_WRITE_TIMEOUT" envDefault:"1s"`
+
+	// Cache config, backend is redis, memory i.e. in process lru of up to max entries results or none
+	CacheBackend    string `env:"CACHE_BACKEND" envDefault:"redis"`
+	CacheMaxEntries int    `env:"CACHE_MAX_ENTRIES" envDefault:"10000"`
+
+	// Stale ttl is time after expiry for which a cached result is served while it is refreshed in background, zero disables it
+	// lock ttl is maximum time for which computation of a result is locked, so that only one instance computes it, zero disables it
+	CacheStaleTTL time.Duration `env:"CACHE_STALE_TTL" envDefault:"0s"`
+	CacheLockTTL  time.Duration `env:"CACHE_LOCK_TTL" envDefault:"30s"`
+
+	// Circuit breaker of redis opens after consecutive failures of the calls, redis is probed at the interval until it recovers
+	// a call of redis fails once call timeout passes, zero means calls wait for the retries of redis client
+	CacheBreakerFailures      int           `env:"CACHE_BREAKER_FAILURES" envDefault:"5"`
+	CacheBreakerProbeInterval time.Duration `env:"CACHE_BREAKER_PROBE_INTERVAL" envDefault:"5s"`
+	CacheCallTimeout          time.Duration `env:"CACHE_CALL_TIMEOUT" envDefault:"500ms"`
+
+	// Maximum time for which a search runs before it fails with search timeout, zero means there is no limit
+	MaxSearchTime time.Duration `env:"MAX_SEARCH_TIME" envDefault:"10s"`
+
+	// Server fails readiness and keeps serving for drain delay once it is asked to stop, so that load balancer stops sending requests,
+	// then it drains in flight requests for up to shutdown timeout, remaining requests are cut after it
+	ShutdownDrainDelay time.Duration `env:"SHUTDOWN_DRAIN_DELAY" envDefault:"5s"`
+	ShutdownTimeout    time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"30s"`
+
+	// City catalog config
+	CityCatalogFile   string `env:"CITY_CATALOG_FILE" envDefault:"data/cities.csv"`
+	CityCatalogStrict bool   `env:"CITY_CATALOG_STRICT" envDefault:"false"`
+
+	// Readiness of the service requires stored flight schedules if it routes against them instead of schedules given in requests
+	ReadinessRequiresTimetable bool `env:"READINESS_REQUIRES_TIMETABLE" envDefault:"false"`
+	// Readiness of the service requires the cache only if configured, as searches are calculated without the cache while it is down
+	ReadinessRequiresCache bool `env:"READINESS_REQUIRES_CACHE" envDefault:"false"`
+
+	// Routing engine used when request does not ask for an engine
+	DefaultEngine string `env:"DEFAULT_ENGINE" envDefault:"dijkstra"`
+
+	// Batch config, trip plans of a batch are searched concurrently by the workers
+	BatchWorkers      int `env:"BATCH_WORKERS" envDefault:"8"`
+	BatchMaxTripPlans int `env:"BATCH_MAX_TRIP_PLANS" envDefault:"500"`
 }
@@ -1,6 +1,7 @@
 package errorconsts
 
 import (
+	"net/http"
 	"strconv"
 )
 
@@ -16,6 +17,38 @@ const (
 	SameStartEndCity = "SameStartEndCity"
 	// InvalidFlightSchedule key
 	InvalidFlightSchedule = "InvalidFlightSchedule"
+	// InvalidObjective key
+	InvalidObjective = "InvalidObjective"
+	// InvalidConnectionRule key
+	InvalidConnectionRule = "InvalidConnectionRule"
+	// ScheduleNotFound key
+	ScheduleNotFound = "ScheduleNotFound"
+	// InvalidFare key
+	InvalidFare = "InvalidFare"
+	// InvalidTime key
+	InvalidTime = "InvalidTime"
+	// InvalidTimeZone key
+	InvalidTimeZone = "InvalidTimeZone"
+	// UnknownCity key
+	UnknownCity = "UnknownCity"
+	// InvalidWaypoint key
+	InvalidWaypoint = "InvalidWaypoint"
+	// InvalidRoundTrip key
+	InvalidRoundTrip = "InvalidRoundTrip"
+	// InvalidSearchConstraint key
+	InvalidSearchConstraint = "InvalidSearchConstraint"
+	// InvalidEngine key
+	InvalidEngine = "InvalidEngine"
+	// InvalidBatchRequest key
+	InvalidBatchRequest = "InvalidBatchRequest"
+	// InvalidExplain key
+	InvalidExplain = "InvalidExplain"
+	// CacheUnavailable key
+	CacheUnavailable = "CacheUnavailable"
+	// SearchTimeout key
+	SearchTimeout = "SearchTimeout"
+	// SearchCanceled key
+	SearchCanceled = "SearchCanceled"
 )
 
 const (
@@ -34,8 +67,43 @@ const (
 	SameStartEndCityCode = 103
 	// InvalidFlightScheduleCode code
 	InvalidFlightScheduleCode = 104
+	// InvalidObjectiveCode code
+	InvalidObjectiveCode = 105
+	// InvalidConnectionRuleCode code
+	InvalidConnectionRuleCode = 106
+	// ScheduleNotFoundCode code
+	ScheduleNotFoundCode = 107
+	// InvalidFareCode code
+	InvalidFareCode = 108
+	// InvalidTimeCode code
+	InvalidTimeCode = 109
+	// InvalidTimeZoneCode code
+	InvalidTimeZoneCode = 110
+	// UnknownCityCode code
+	UnknownCityCode = 111
+	// InvalidWaypointCode code
+	InvalidWaypointCode = 112
+	// InvalidRoundTripCode code
+	InvalidRoundTripCode = 113
+	// InvalidSearchConstraintCode code
+	InvalidSearchConstraintCode = 114
+	// InvalidEngineCode code
+	InvalidEngineCode = 115
+	// InvalidBatchRequestCode code
+	InvalidBatchRequestCode = 116
+	// InvalidExplainCode code
+	InvalidExplainCode = 117
+	// CacheUnavailableCode code
+	CacheUnavailableCode = 118
+	// SearchTimeoutCode code
+	SearchTimeoutCode = 119
+	// SearchCanceledCode code
+	SearchCanceledCode = 120
 )
 
+// StatusClientClosedRequest is http status of a request which is closed by the client before the server responds
+const StatusClientClosedRequest = 499
+
 // LTError is custom error for the micro service
 type LTError struct {
 	Message  string `json:"message"`
@@ -55,6 +123,26 @@ func (err LTError) Error() string {
 	return message
 }
 
+// GetLTError gets LTError of the error from LTErrorMap, an error which is not in the map gets generic LTError
+// missing http code is bad request and missing error is the error itself
+func GetLTError(err error) LTError {
+	ltError, ok := LTErrorMap[err.Error()]
+	if !ok {
+		ltError = LTError{
+			Message: GenericErrorMessage,
+			Code:    GenericErrorCode,
+		}
+	}
+
+	if ltError.HTTPCode == 0 {
+		ltError.HTTPCode = http.StatusBadRequest
+	}
+	if ltError.Err == "" {
+		ltError.Err = err.Error()
+	}
+	return ltError
+}
+
 // LTErrorMap is a map of error strings against LTError struct instances
 var LTErrorMap = map[string]LTError{
 	InvalidRequest: {
@@ -73,4 +161,72 @@ var LTErrorMap = map[string]LTError{
 		Message: "One or more flight schedule provided in the request are invalid. Please make sure each flight schedule has valid arrival and departure details.",
 		Code:    InvalidFlightScheduleCode,
 	},
+	InvalidObjective: {
+		Message: "Invalid objective. Objective can be one of min_duration, earliest_arrival, latest_departure or pareto.",
+		Code:    InvalidObjectiveCode,
+	},
+	InvalidConnectionRule: {
+		Message: "Invalid connection rule. Minimum connection time and maximum layover cannot be negative and minimum connection time cannot be more than maximum layover.",
+		Code:    InvalidConnectionRuleCode,
+	},
+	ScheduleNotFound: {
+		Message:  "Flight schedule not found.",
+		Code:     ScheduleNotFoundCode,
+		HTTPCode: http.StatusNotFound,
+	},
+	InvalidFare: {
+		Message: "Invalid fare. Fare amount cannot be negative and all the fares must be in same currency.",
+		Code:    InvalidFareCode,
+	},
+	InvalidTime: {
+		Message: "Invalid time. Time must be ISO-8601 date time with offset, local time must be ISO-8601 date time without offset that exists only once in the time zone of the city.",
+		Code:    InvalidTimeCode,
+	},
+	InvalidTimeZone: {
+		Message: "Invalid time zone. Time zone must be a valid IANA time zone and it is required for every city having local time.",
+		Code:    InvalidTimeZoneCode,
+	},
+	UnknownCity: {
+		Message: "Unknown city. City must be a code, name or alias of a city or an airport in the city catalog.",
+		Code:    UnknownCityCode,
+	},
+	InvalidWaypoint: {
+		Message: "Invalid waypoint. Each waypoint must have a city different from the previous city of the trip and minimum stay cannot be negative.",
+		Code:    InvalidWaypointCode,
+	},
+	InvalidRoundTrip: {
+		Message: "Invalid round trip. Round trip cannot have waypoints, windows must start before they end and minimum stay cannot be more than maximum stay.",
+		Code:    InvalidRoundTripCode,
+	},
+	InvalidSearchConstraint: {
+		Message: "Invalid search constraint. Maximum stops cannot be negative and a carrier cannot be both excluded and preferred.",
+		Code:    InvalidSearchConstraintCode,
+	},
+	InvalidEngine: {
+		Message: "Invalid engine. Engine can be one of dijkstra, csa or brute_force, csa does not support pareto objective.",
+		Code:    InvalidEngineCode,
+	},
+	InvalidBatchRequest: {
+		Message: "Invalid batch request. Please provide trip plans instead of a trip plan, and not more trip plans than allowed in a batch.",
+		Code:    InvalidBatchRequestCode,
+	},
+	InvalidExplain: {
+		Message: "Explanation is not available for multi city trips and round trips.",
+		Code:    InvalidExplainCode,
+	},
+	CacheUnavailable: {
+		Message:  "Cache is unavailable. Flight schedules cannot be stored or read until it recovers, please try again later.",
+		Code:     CacheUnavailableCode,
+		HTTPCode: http.StatusServiceUnavailable,
+	},
+	SearchTimeout: {
+		Message:  "Search took longer than the maximum search time. Please narrow the search e.g. by limiting stops, carriers or time window and try again.",
+		Code:     SearchTimeoutCode,
+		HTTPCode: http.StatusGatewayTimeout,
+	},
+	SearchCanceled: {
+		Message:  "Search is canceled as the request was closed before it completed.",
+		Code:     SearchCanceledCode,
+		HTTPCode: StatusClientClosedRequest,
+	},
 }
@@ -5,4 +5,96 @@ const (
 	LazyJack = "lazy-jack"
 	// FlightPlan .
 	FlightPlan = "flight_plan"
+	// FlightPlans .
+	FlightPlans = "flight_plans"
+	// Itineraries .
+	Itineraries = "itineraries"
+	// Results .
+	Results = "results"
+	// Explanation .
+	Explanation = "explanation"
+	// ScheduleStore .
+	ScheduleStore = "schedule-store"
+	// Cache .
+	Cache = "cache"
+	// Status .
+	Status = "status"
+	// Schedule .
+	Schedule = "schedule"
+	// Schedules .
+	Schedules = "schedules"
+)
+
+// routing objectives supported by the search
+const (
+	// MinDuration minimizes total duration of the trip including layovers
+	MinDuration = "min_duration"
+	// EarliestArrival minimizes arrival time at destination
+	EarliestArrival = "earliest_arrival"
+	// LatestDeparture maximizes departure time from source
+	LatestDeparture = "latest_departure"
+	// Pareto returns all the pareto optimal paths across duration, fare and number of stops
+	Pareto = "pareto"
+)
+
+// routing engines which can be used for the search
+const (
+	// Dijkstra searches time expanded graph of the flights using dijkstra's algorithm
+	Dijkstra = "dijkstra"
+	// CSA scans timetable of the flights sorted by departure using connection scan algorithm
+	CSA = "csa"
+	// BruteForce enumerates every path of the flights, it is a reference to cross check other engines on small timetables
+	BruteForce = "brute_force"
+)
+
+// cache backends which can store the results and flight schedules
+const (
+	// RedisCache stores them in redis, which is shared by all the instances of the service
+	RedisCache = "redis"
+	// MemoryCache stores them in memory of the process, results are evicted in least recently used order
+	MemoryCache = "memory"
+	// NoCache does not store them at all
+	NoCache = "none"
+)
+
+// states of the circuit breaker of the cache
+const (
+	// BreakerClosed means cache is healthy and it is called
+	BreakerClosed = "closed"
+	// BreakerOpen means cache is unhealthy and it is skipped until it recovers
+	BreakerOpen = "open"
+)
+
+// connection modes of redis
+const (
+	// RedisStandalone connects to a single redis server
+	RedisStandalone = "standalone"
+	// RedisSentinel connects to the master found by redis sentinels, which fail over to a replica if master fails
+	RedisSentinel = "sentinel"
+	// RedisCluster connects to the nodes of redis cluster, keys are sharded across the nodes
+	RedisCluster = "cluster"
+)
+
+// statuses of the service and of its components
+const (
+	// Alive means process of the service is running
+	Alive = "alive"
+	// Ready means service can serve requests
+	Ready = "ready"
+	// NotReady means a required component of the service is down
+	NotReady = "not_ready"
+	// ShuttingDown means service is shutting down and does not take new requests
+	ShuttingDown = "shutting_down"
+	// Up means component is working
+	Up = "up"
+	// Down means component is not working
+	Down = "down"
+)
+
+// components the service depends on
+const (
+	// CircuitBreaker is the circuit breaker of the cache
+	CircuitBreaker = "circuit_breaker"
+	// Timetable is the stored flight schedules
+	Timetable = "timetable"
 )
@@ -0,0 +1,135 @@
+package flightpath
+
+import (
+	"context"
+	"errors"
+	"github.com/somprabhsharma/the-lazy-traveler/constants"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/errorconsts"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
+	"strconv"
+	"sync"
+)
+
+// FindBatchItineraries finds shortest itineraries of every trip plan of the batch over the same flight schedules
+// schedules are converted into the network of the engine once, which is then searched for the trip plans concurrently by a pool of workers
+// an error of a trip plan is returned in its result, only the errors of the whole batch e.g. invalid schedules are returned as error
+// whole batch shares the maximum search time, trip plans which are not searched within it get search timeout or search canceled error
+func (c *Controller) FindBatchItineraries(ctx context.Context, data flightpath.BatchRequest) ([]*flightpath.BatchResult, error) {
+	ctx, cancel := withSearchDeadline(ctx)
+	defer cancel()
+
+	if data.TripPlan != nil || len(data.TripPlans) == 0 || len(data.TripPlans) > constants.Env.BatchMaxTripPlans {
+		return nil, errors.New(errorconsts.InvalidBatchRequest)
+	}
+
+	// route against stored flight schedules if schedules are not provided in the request
+	search := data.LazyJackRequest
+	err := c.loadStoredSchedules(&search)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := c.newSearchOptions(search, search.K)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduleNetwork, timeZones, err := c.buildNetwork(search, options)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := constants.Env.BatchWorkers
+	if workers > len(data.TripPlans) {
+		workers = len(data.TripPlans)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	// every worker writes results of different trip plans, so results do not need any lock
+	results := make([]*flightpath.BatchResult, len(data.TripPlans))
+	tripPlans := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range tripPlans {
+				tripData := search
+				tripData.TripPlan = data.TripPlans[j]
+
+				itineraries, err := c.findBatchItineraries(ctx, tripData, scheduleNetwork, options, timeZones)
+				if err != nil && err == ctx.Err() {
+					// trip plan which is not searched in time gets the error of the search once all the workers have stopped
+					continue
+				}
+
+				results[j] = &flightpath.BatchResult{TripPlan: tripData.TripPlan}
+				if err != nil {
+					ltError := errorconsts.GetLTError(err)
+					results[j].Error = &ltError
+					continue
+				}
+				results[j].Itineraries = itineraries
+			}
+		}()
+	}
+	// remaining trip plans are not searched once the context is done
+	for i := 0; i < len(data.TripPlans) && ctx.Err() == nil; i++ {
+		select {
+		case tripPlans <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(tripPlans)
+	wg.Wait()
+
+	// trip plans which are not searched in time fail with search timeout or search canceled, while the searched ones keep their results
+	unfinished := 0
+	for j, result := range results {
+		if result == nil {
+			ltError := errorconsts.GetLTError(getSearchError(ctx.Err()))
+			results[j] = &flightpath.BatchResult{TripPlan: data.TripPlans[j], Error: &ltError}
+			unfinished++
+		}
+	}
+	if unfinished != 0 {
+		logger.Warn(literals.LazyJack, strconv.Itoa(unfinished)+" trip plans of the batch were not searched in time", ctx.Err(), nil)
+	}
+
+	logger.Info(literals.LazyJack, "successfully searched "+strconv.Itoa(len(results)-unfinished)+" trip plans of the batch", nil)
+	return results, nil
+}
+
+// findBatchItineraries finds shortest itineraries of a trip plan of the batch over the network of the batch
+// multi city trips and round trips search different schedules for each of their segments, so they are searched on their own
+func (c *Controller) findBatchItineraries(ctx context.Context, data flightpath.LazyJackRequest, scheduleNetwork network, options searchOptions, timeZones cityTimeZones) ([]*flightpath.Itinerary, error) {
+	err := validateTripPlan(data.TripPlan)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.RoundTrip != nil || len(data.TripPlan.Waypoints) != 0 {
+		return c.findItineraries(ctx, data, data.K)
+	}
+
+	sources, destinations, err := c.getTripCities(data.TripPlan)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := findLooplessPaths(ctx, scheduleNetwork, sources, destinations, options)
+	if err != nil {
+		return nil, err
+	}
+	itineraries, err := getItineraries(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	timeZones.renderItineraries(itineraries)
+	return itineraries, nil
+}
@@ -0,0 +1,132 @@
+package flightpath
+
+import (
+	"context"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
+	"sort"
+	"strconv"
+)
+
+// bruteForceEngine enumerates every path of the flight schedules and picks the paths as per the options
+// number of paths grows exponentially with number of flights, so it is only meant as a reference to cross check other engines
+type bruteForceEngine struct{}
+
+// supports tells if the engine can search under the objective, brute force supports every objective
+func (bruteForceEngine) supports(objective string) bool {
+	return true
+}
+
+// newNetwork converts the schedules into timetable, which has the flights without any duplicates
+func (bruteForceEngine) newNetwork(schedules []*flightpath.FlightDetail) (network, error) {
+	scheduleTimetable, err := generateTimetableOfSchedules(schedules)
+	if err != nil {
+		return nil, err
+	}
+	return bruteForceNetwork{scheduleTimetable}, nil
+}
+
+// bruteForceNetwork is the timetable searched by brute force
+type bruteForceNetwork struct {
+	*timetable
+}
+
+// findPaths enumerates every path from any of the source cities to any of the destination cities and picks the paths as per the options
+func (n bruteForceNetwork) findPaths(ctx context.Context, sources, destinations []string, options searchOptions) ([]directPath, error) {
+	paths, err := n.getAllPaths(ctx, sources, destinations, options)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info(literals.LazyJack, "successfully enumerated "+strconv.Itoa(len(paths))+" paths by brute force", nil)
+
+	sort.Sort(path(paths))
+	switch {
+	case options.allArrivals:
+		paths = getShortestPathsByArrival(paths, options)
+	case options.objective == literals.Pareto:
+		paths = getParetoOptimalPaths(paths, options)
+	case len(paths) > options.k:
+		paths = paths[:options.k]
+	}
+	return paths, nil
+}
+
+// getAllPaths gets every path from any of the source cities to any of the destination cities which satisfies the options
+// a path does not fly back to a city it has visited and does not fly any further once it has reached the destination
+// enumeration stops once the context is done, as number of paths can be too large to enumerate
+func (t *timetable) getAllPaths(ctx context.Context, sources, destinations []string, options searchOptions) ([]directPath, error) {
+	isDestination := newCitySet(destinations)
+	visitedCities := make([]bool, len(t.cities))
+	paths := make([]directPath, 0)
+
+	var err error
+	var takeNextFlights func(p directPath)
+	takeNextFlights = func(p directPath) {
+		if err != nil {
+			return
+		}
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		if isDestination[p.node.City] {
+			options.trace.addCandidate(p)
+			paths = append(paths, p)
+			return
+		}
+		options.trace.expandNode()
+		for _, c := range t.connections {
+			if visitedCities[c.arrivalCity] || t.cities[c.departureCity] != p.node.City || !p.canTakeFlight(c.edge.OriginFlightTimestamp, c.edge.Flight, options) {
+				continue
+			}
+			visitedCities[c.arrivalCity] = true
+			takeNextFlights(p.takeFlight(c.edge, options))
+			visitedCities[c.arrivalCity] = false
+		}
+	}
+
+	for _, source := range sources {
+		// source which is not in the timetable does not have any flight, hence it can never be visited again
+		index, ok := t.cityIndexes[source]
+		if ok {
+			visitedCities[index] = true
+		}
+		takeNextFlights(directPath{node: flightpath.ScheduleDetail{City: source}})
+		if ok {
+			visitedCities[index] = false
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// getShortestPathsByArrival gets the shortest path to every arrival at the destination cities from the ranked paths
+func getShortestPathsByArrival(paths []directPath, options searchOptions) []directPath {
+	arrivals := make(map[string]bool)
+	shortestPaths := make([]directPath, 0)
+	for _, p := range paths {
+		nodeKey := options.nodeKey(p)
+		if !arrivals[nodeKey] {
+			arrivals[nodeKey] = true
+			shortestPaths = append(shortestPaths, p)
+		}
+	}
+	return shortestPaths
+}
+
+// getParetoOptimalPaths gets the pareto optimal paths from the ranked paths, zero k means there is no limit on number of paths
+// a path can only be dominated by the paths ranked before it, as paths are ranked by duration, fare and then by number of stops
+func getParetoOptimalPaths(paths []directPath, options searchOptions) []directPath {
+	paretoPaths := make([]directPath, 0)
+	for _, p := range paths {
+		if options.k != 0 && len(paretoPaths) == options.k {
+			break
+		}
+		if !isDominated(p, paretoPaths) {
+			paretoPaths = append(paretoPaths, p)
+		}
+	}
+	return paretoPaths
+}
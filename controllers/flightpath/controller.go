@@ -1,6 +1,7 @@
 package flightpath
 
 import (
+	"context"
 	"errors"
 	"github.com/somprabhsharma/the-lazy-traveler/constants/errorconsts"
 	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
@@ -8,6 +9,7 @@ import (
 	"github.com/somprabhsharma/the-lazy-traveler/models"
 	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
 	"strconv"
+	"strings"
 )
 
 // Controller is a struct which will act like a controller
@@ -23,75 +25,289 @@ func NewController(dao *models.Dao) *Controller {
 }
 
 // FindShortestFlightPath finds shortest flight path for given data
-func (c *Controller) FindShortestFlightPath(data flightpath.LazyJackRequest) ([]flightpath.ScheduleDetail, error) {
-	if data.TripPlan.StartCity == data.TripPlan.EndCity {
-		return nil, errors.New(errorconsts.SameStartEndCity)
+// search fails with search timeout after maximum search time and with search canceled once the context is canceled
+func (c *Controller) FindShortestFlightPath(ctx context.Context, data flightpath.LazyJackRequest) ([]flightpath.ScheduleDetail, error) {
+	ctx, cancel := withSearchDeadline(ctx)
+	defer cancel()
+
+	err := validateTripPlan(data.TripPlan)
+	if err != nil {
+		return nil, err
+	}
+
+	// route against stored flight schedules if schedules are not provided in the request
+	err = c.loadStoredSchedules(&data)
+	if err != nil {
+		return nil, err
 	}
 
-	// get shortest path data from cache if present
-	shortestPath, err := c.Dao.FlightPathModel.Get(data)
-	if err == nil && shortestPath != nil {
-		logger.Info(literals.LazyJack, "returning shortest path from cache", shortestPath)
+	// get shortest path data from cache if present, otherwise calculate it and save it in cache
+	// computation is shared by the concurrent requests of the same search, hence it has a deadline of its own
+	shortestPath, err := c.Dao.FlightPathModel.GetOrCompute(ctx, data, func(ctx context.Context) ([]flightpath.ScheduleDetail, error) {
+		ctx, cancel := withSearchDeadline(ctx)
+		defer cancel()
+		logger.Info(literals.LazyJack, "calculating shortest path", nil)
+
+		// select the relevant path among shortest paths
+		itineraries, err := c.findItineraries(ctx, data, 1)
+		if err != nil {
+			return nil, err
+		}
+		shortestPath := getShortestPath(itineraries)
+
+		logger.Info(literals.LazyJack, "successfully calculated shortest path: ", shortestPath)
 		return shortestPath, nil
+	})
+	if err != nil {
+		return nil, getSearchError(err)
+	}
+	return shortestPath, nil
+}
+
+// FindFlightPaths finds k shortest flight paths for given data, where k is taken from the request
+// paths are ranked by total duration and paths with same duration are ranked by number of stops
+func (c *Controller) FindFlightPaths(ctx context.Context, data flightpath.LazyJackRequest) ([][]flightpath.ScheduleDetail, error) {
+	itineraries, err := c.FindItineraries(ctx, data)
+	if err != nil {
+		return nil, err
 	}
 
-	logger.Info(literals.LazyJack, "calculating shortest path", nil)
+	flightPaths := make([][]flightpath.ScheduleDetail, 0, len(itineraries))
+	for _, itinerary := range itineraries {
+		flightPaths = append(flightPaths, getFlightPlan(itinerary))
+	}
+	return flightPaths, nil
+}
 
-	// filter flight schedules
-	data.Schedules, err = filterFlightSchedules(data.Schedules, data.PreferredTime)
+// FindItineraries finds k shortest itineraries for given data, where k is taken from the request and defaults to 1
+// for pareto objective, all the pareto optimal itineraries are found unless k is given
+// each itinerary has separate legs along with its total in air time, layover time, number of stops and fare
+// search fails with search timeout after maximum search time and with search canceled once the context is canceled
+func (c *Controller) FindItineraries(ctx context.Context, data flightpath.LazyJackRequest) ([]*flightpath.Itinerary, error) {
+	ctx, cancel := withSearchDeadline(ctx)
+	defer cancel()
+
+	err := validateTripPlan(data.TripPlan)
 	if err != nil {
 		return nil, err
 	}
 
-	// convert schedules array into graph
-	scheduleGraph, err := generateGraphOfSchedules(data.Schedules)
+	// route against stored flight schedules if schedules are not provided in the request
+	err = c.loadStoredSchedules(&data)
 	if err != nil {
 		return nil, err
 	}
 
-	// generate source and destination city parameters
-	source := flightpath.ScheduleDetail{
-		City: data.TripPlan.StartCity,
+	// get itineraries from cache if present, otherwise calculate them and save them in cache
+	// computation is shared by the concurrent requests of the same search, hence it has a deadline of its own
+	itineraries, err := c.Dao.FlightPathModel.GetOrComputeItineraries(ctx, data, func(ctx context.Context) ([]*flightpath.Itinerary, error) {
+		ctx, cancel := withSearchDeadline(ctx)
+		defer cancel()
+		logger.Info(literals.LazyJack, "calculating shortest itineraries", nil)
+
+		itineraries, err := c.findItineraries(ctx, data, data.K)
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Info(literals.LazyJack, "successfully calculated itineraries: ", itineraries)
+		return itineraries, nil
+	})
+	if err != nil {
+		return nil, getSearchError(err)
 	}
-	destination := flightpath.ScheduleDetail{
-		City: data.TripPlan.EndCity,
+	return itineraries, nil
+}
+
+// findItineraries runs the search over the schedules of given data and returns up to k shortest itineraries, zero k means default limit
+// multi city trip is searched segment by segment and round trip is searched both ways, only the best trip is returned for both of them
+func (c *Controller) findItineraries(ctx context.Context, data flightpath.LazyJackRequest, k int) ([]*flightpath.Itinerary, error) {
+	if data.RoundTrip != nil {
+		return c.findRoundTripItineraries(ctx, data)
+	}
+	if len(data.TripPlan.Waypoints) != 0 {
+		return c.findMultiCityItineraries(ctx, data)
 	}
 
-	// execute dijkstra's algorithm to get array of paths from source to destination
-	shortestDuration, paths := scheduleGraph.getShortestPaths(source, destination)
+	options, err := c.newSearchOptions(data, k)
+	if err != nil {
+		return nil, err
+	}
 
-	logger.Info(literals.LazyJack, "successfully applied dijkstra's algorithm and shortestDuration is: "+strconv.FormatInt(shortestDuration, 10)+" with paths: ", paths)
+	paths, timeZones, err := c.findPaths(ctx, data, options)
+	if err != nil {
+		return nil, err
+	}
 
-	// select the relevant paths among shortest paths
-	shortestPath, err = getShortestPath(shortestDuration, paths)
+	itineraries, err := getItineraries(paths)
 	if err != nil {
 		return nil, err
 	}
 
-	// save this shortest path in redis
-	_ = c.Dao.FlightPathModel.Put(shortestPath, data)
+	// render utc & local times of the flight plans if flight schedules are given in time or local time
+	timeZones.renderItineraries(itineraries)
+	return itineraries, nil
+}
 
-	logger.Info(literals.LazyJack, "successfully calculated shortest path: ", shortestPath)
-	return shortestPath, nil
+// findPaths runs the search over the schedules of given data as per the options and returns the paths along with time zones of the cities
+func (c *Controller) findPaths(ctx context.Context, data flightpath.LazyJackRequest, options searchOptions) ([]directPath, cityTimeZones, error) {
+	scheduleNetwork, timeZones, err := c.buildNetwork(data, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// generate source and destination city parameters
+	// a trip to or from a metro area can use any of its airports
+	sources, destinations, err := c.getTripCities(data.TripPlan)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paths, err := findLooplessPaths(ctx, scheduleNetwork, sources, destinations, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	return paths, timeZones, nil
+}
+
+// buildNetwork converts the schedules of given data into the network searched by the engine of the options along with time zones of the cities
+// flights departing before preferred time or arriving after arrive by are left out, network can be searched from any later time with depart after option
+func (c *Controller) buildNetwork(data flightpath.LazyJackRequest, options searchOptions) (network, cityTimeZones, error) {
+	// convert time & local time of flight schedules into timestamps
+	schedules, timeZones, err := normalizeSchedules(data.Schedules, data.TimeZones)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// filter flight schedules
+	schedules, err = filterFlightSchedules(schedules, data.PreferredTime, data.ArriveBy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// convert schedules array into the network searched by the engine
+	scheduleNetwork, err := options.engine.newNetwork(schedules)
+	if err != nil {
+		return nil, nil, err
+	}
+	return scheduleNetwork, timeZones, nil
+}
+
+// getTripCities gets the cities from where the trip can start and where it can end
+// trip cannot start and end in same metro area, e.g. from a metro area to one of its own airports
+func (c *Controller) getTripCities(tripPlan *flightpath.TripDetail) ([]string, []string, error) {
+	sources := c.Dao.CityCatalog.Airports(tripPlan.StartCity)
+	destinations := c.Dao.CityCatalog.Airports(tripPlan.EndCity)
+
+	isSource := newCitySet(sources)
+	for _, destination := range destinations {
+		if isSource[destination] {
+			return nil, nil, errors.New(errorconsts.SameStartEndCity)
+		}
+	}
+	return sources, destinations, nil
+}
+
+// loadStoredSchedules loads the stored flight schedules in data if data does not have any flight schedule
+// version of the stored schedules becomes part of cache key instead of the schedules, so any change in them invalidates the cache
+// schedules are read from the store only when its version changes, hence loaded schedules are shared and must not be modified
+func (c *Controller) loadStoredSchedules(data *flightpath.LazyJackRequest) error {
+	if len(data.Schedules) != 0 {
+		return nil
+	}
+
+	schedules, version, err := c.Dao.ScheduleModel.ListWithVersion()
+	if err != nil {
+		return err
+	}
+	data.Schedules = schedules
+	data.StoredSchedulesVersion = &version
+	return nil
 }
 
 // generateGraphOfSchedules converts flight schedules into graph data structure
 func generateGraphOfSchedules(schedules []*flightpath.FlightDetail) (*graph, error) {
+	uniqueSchedules, err := getUniqueSchedules(schedules)
+	if err != nil {
+		return nil, err
+	}
+
 	graph := newGraph()
+	for _, schedule := range uniqueSchedules {
+		duration := schedule.Arrival.Timestamp - schedule.Departure.Timestamp
+		graph.addEdge(*schedule.Departure, *schedule.Arrival, duration, getFlightIdentity(schedule), schedule.Fare)
+	}
+
+	return graph, nil
+}
+
+// getUniqueSchedules validates the flight schedules and removes duplicates among them
+// identical flight schedules are treated as one, so that same flight plan is not returned more than once
+func getUniqueSchedules(schedules []*flightpath.FlightDetail) ([]*flightpath.FlightDetail, error) {
+	uniqueSchedules := make([]*flightpath.FlightDetail, 0, len(schedules))
+	addedSchedules := make(map[string]bool)
+	currency := ""
 	for _, schedule := range schedules {
 		if schedule.Arrival == nil || schedule.Departure == nil {
 			return nil, errors.New(errorconsts.InvalidFlightSchedule)
 		}
-		duration := schedule.Arrival.Timestamp - schedule.Departure.Timestamp
-		graph.addEdge(*schedule.Departure, *schedule.Arrival, duration)
+
+		// all the fares must be in same currency to be able to add them
+		if schedule.Fare != nil {
+			if schedule.Fare.Amount < 0 || schedule.Fare.Currency == "" {
+				return nil, errors.New(errorconsts.InvalidFare)
+			}
+			if currency != "" && currency != schedule.Fare.Currency {
+				return nil, errors.New(errorconsts.InvalidFare)
+			}
+			currency = schedule.Fare.Currency
+		}
+
+		scheduleKey := getScheduleKey(*schedule.Departure, *schedule.Arrival, getFlightIdentity(schedule), schedule.Fare)
+		if addedSchedules[scheduleKey] {
+			continue
+		}
+		addedSchedules[scheduleKey] = true
+		uniqueSchedules = append(uniqueSchedules, schedule)
 	}
 
-	return graph, nil
+	return uniqueSchedules, nil
+}
+
+// getScheduleKey gets a key which identifies a flight schedule by its departure, arrival, flight identity and fare
+func getScheduleKey(departure, arrival flightpath.ScheduleDetail, flight *flightpath.FlightIdentity, fare *flightpath.Fare) string {
+	if flight == nil {
+		flight = &flightpath.FlightIdentity{}
+	}
+	fareKey := ""
+	if fare != nil {
+		fareKey = strconv.FormatInt(getFareInMinorUnits(fare), 10) + fare.Currency
+	}
+	return strings.Join([]string{
+		departure.City, strconv.FormatInt(departure.Timestamp, 10),
+		arrival.City, strconv.FormatInt(arrival.Timestamp, 10),
+		flight.ID, flight.FlightNumber, flight.Carrier, flight.Aircraft, fareKey,
+	}, "_")
 }
 
-// filterFlightSchedules filters flight schedule by cutoffTimestamp i.e. returns flights that have departure time after cutoffTimestamp
-func filterFlightSchedules(schedules []*flightpath.FlightDetail, cutoffTimestamp int64) ([]*flightpath.FlightDetail, error) {
-	if cutoffTimestamp == 0 {
+// getFlightIdentity gets identity of the flight from its schedule, returns nil if schedule does not have any identity
+func getFlightIdentity(schedule *flightpath.FlightDetail) *flightpath.FlightIdentity {
+	if schedule.ID == "" && schedule.FlightNumber == "" && schedule.Carrier == "" && schedule.Aircraft == "" {
+		return nil
+	}
+	return &flightpath.FlightIdentity{
+		ID:           schedule.ID,
+		FlightNumber: schedule.FlightNumber,
+		Carrier:      schedule.Carrier,
+		Aircraft:     schedule.Aircraft,
+	}
+}
+
+// filterFlightSchedules filters flight schedule by cutoffTimestamp & deadlineTimestamp
+// i.e. returns flights that have departure time after cutoffTimestamp and arrival time before deadlineTimestamp
+// zero value of cutoffTimestamp or deadlineTimestamp means there is no such limit
+func filterFlightSchedules(schedules []*flightpath.FlightDetail, cutoffTimestamp, deadlineTimestamp int64) ([]*flightpath.FlightDetail, error) {
+	if cutoffTimestamp == 0 && deadlineTimestamp == 0 {
 		return schedules, nil
 	}
 
@@ -105,38 +321,32 @@ func filterFlightSchedules(schedules []*flightpath.FlightDetail, cutoffTimestamp
 			return nil, errors.New(errorconsts.InvalidFlightSchedule)
 		}
 
-		if schedule.Departure.Timestamp >= cutoffTimestamp {
-			filteredSchedules = append(filteredSchedules, schedule)
+		if schedule.Departure.Timestamp < cutoffTimestamp {
+			continue
 		}
+		if deadlineTimestamp != 0 && schedule.Arrival.Timestamp > deadlineTimestamp {
+			continue
+		}
+		filteredSchedules = append(filteredSchedules, schedule)
 	}
 	return filteredSchedules, nil
 }
 
-// getShortestPath returns one single most relevant flight path among all the shortest path
-func getShortestPath(shortestDuration int64, paths map[int64][][]flightpath.ScheduleDetail) ([]flightpath.ScheduleDetail, error) {
-	if paths == nil || len(paths) == 0 {
-		return nil, errors.New(errorconsts.NoFlightsAvailable)
-	}
-	shortestPaths := paths[shortestDuration]
-	if shortestPaths == nil || len(shortestPaths) == 0 {
-		return nil, errors.New(errorconsts.NoFlightsAvailable)
-	}
+// getShortestPath returns one single most relevant flight path among all the shortest itineraries
+// itineraries are already ranked by duration and number of stops, so the first itinerary is the most relevant one
+func getShortestPath(itineraries []*flightpath.Itinerary) []flightpath.ScheduleDetail {
+	return getFlightPlan(itineraries[0])
+}
 
-	// if only path available then it is the shortest path
-	if len(shortestPaths) == 1 {
-		return shortestPaths[0], nil
+// getItineraries returns itineraries of all the shortest paths in their ranked order
+func getItineraries(paths []directPath) ([]*flightpath.Itinerary, error) {
+	if len(paths) == 0 {
+		return nil, errors.New(errorconsts.NoFlightsAvailable)
 	}
 
-	// there can be multiple shortest path which can have same number of stops
-	// but we are picking the first one, since the api response can only contain one flight path
-	shortestPathIndex := 0
-	shortestPathLength := len(shortestPaths[0])
-	for index, path := range shortestPaths {
-		if len(path) < shortestPathLength {
-			shortestPathLength = len(path)
-			shortestPathIndex = index
-		}
+	itineraries := make([]*flightpath.Itinerary, 0, len(paths))
+	for _, p := range paths {
+		itineraries = append(itineraries, p.itinerary())
 	}
-
-	return shortestPaths[shortestPathIndex], nil
+	return itineraries, nil
 }
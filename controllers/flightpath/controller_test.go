@@ -1,12 +1,23 @@
 package flightpath
 
 import (
+	"bytes"
+	"context"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/somprabhsharma/the-lazy-traveler/constants"
 	"github.com/somprabhsharma/the-lazy-traveler/constants/errorconsts"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
 	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
 	"github.com/somprabhsharma/the-lazy-traveler/models"
+	"github.com/somprabhsharma/the-lazy-traveler/models/cache"
+	"github.com/somprabhsharma/the-lazy-traveler/models/catalog"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
 	"testing"
+	"time"
 )
 
 func TestFlightPathController(t *testing.T) {
@@ -15,8 +26,9 @@ func TestFlightPathController(t *testing.T) {
 }
 
 var _ = Describe("controllers", func() {
+	ctx := context.Background()
 	Context("##flightpath", func() {
-		controller := NewController(models.NewDao())
+		controller := NewController(models.NewDaoWithCache(cache.NewMemory(0)))
 		data := flightpath.LazyJackRequest{
 			Schedules: []*flightpath.FlightDetail{
 				{
@@ -61,7 +73,7 @@ var _ = Describe("controllers", func() {
 				StartCity: "A",
 				EndCity:   "A",
 			}
-			shortestPath, err := controller.FindShortestFlightPath(data)
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
 			Expect(shortestPath).Should(BeNil())
 			Expect(err).ShouldNot(BeNil())
 			Expect(err.Error()).To(Equal(errorconsts.SameStartEndCity))
@@ -72,7 +84,7 @@ var _ = Describe("controllers", func() {
 				StartCity: "A",
 				EndCity:   "AA",
 			}
-			shortestPath, err := controller.FindShortestFlightPath(data)
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
 			Expect(shortestPath).Should(BeNil())
 			Expect(err).ShouldNot(BeNil())
 			Expect(err.Error()).To(Equal(errorconsts.NoFlightsAvailable))
@@ -83,7 +95,7 @@ var _ = Describe("controllers", func() {
 				StartCity: "A",
 				EndCity:   "Z",
 			}
-			shortestPath, err := controller.FindShortestFlightPath(data)
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
 			Expect(err).Should(BeNil())
 			Expect(shortestPath).ShouldNot(BeNil())
 			Expect(len(shortestPath)).To(Equal(2))
@@ -99,7 +111,7 @@ var _ = Describe("controllers", func() {
 				EndCity:   "Z",
 			}
 			data.PreferredTime = int64(2)
-			shortestPath, err := controller.FindShortestFlightPath(data)
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
 			Expect(err).Should(BeNil())
 			Expect(shortestPath).ShouldNot(BeNil())
 			Expect(len(shortestPath)).To(Equal(3))
@@ -144,7 +156,7 @@ var _ = Describe("controllers", func() {
 					},
 				},
 			}
-			shortestPath, err := controller.FindShortestFlightPath(data)
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
 			Expect(shortestPath).Should(BeNil())
 			Expect(err).ShouldNot(BeNil())
 			Expect(err.Error()).To(Equal(errorconsts.InvalidFlightSchedule))
@@ -173,7 +185,7 @@ var _ = Describe("controllers", func() {
 					},
 				},
 			}
-			shortestPath, err := controller.FindShortestFlightPath(data)
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
 			Expect(shortestPath).Should(BeNil())
 			Expect(err).ShouldNot(BeNil())
 			Expect(err.Error()).To(Equal(errorconsts.InvalidFlightSchedule))
@@ -216,7 +228,7 @@ var _ = Describe("controllers", func() {
 					},
 				},
 			}
-			shortestPath, err := controller.FindShortestFlightPath(data)
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
 			Expect(shortestPath).Should(BeNil())
 			Expect(err).ShouldNot(BeNil())
 			Expect(err.Error()).To(Equal(errorconsts.InvalidFlightSchedule))
@@ -259,10 +271,1650 @@ var _ = Describe("controllers", func() {
 					},
 				},
 			}
-			shortestPath, err := controller.FindShortestFlightPath(data)
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
 			Expect(shortestPath).Should(BeNil())
 			Expect(err).ShouldNot(BeNil())
 			Expect(err.Error()).To(Equal(errorconsts.InvalidFlightSchedule))
 		})
+
+		It("should throw error if any flight schedule provided arrives before its departure", func() {
+			data.TripPlan = &flightpath.TripDetail{
+				StartCity: "A",
+				EndCity:   "Z",
+			}
+			data.Schedules = []*flightpath.FlightDetail{
+				{
+					Departure: &flightpath.ScheduleDetail{
+						City:      "A",
+						Timestamp: 10,
+					},
+					Arrival: &flightpath.ScheduleDetail{
+						City:      "Z",
+						Timestamp: 5,
+					},
+				},
+			}
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
+			Expect(shortestPath).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.InvalidFlightSchedule))
+
+			data.Schedules[0].Departure = &flightpath.ScheduleDetail{
+				City: "A",
+				Time: "2020-01-01T10:00:00+05:30",
+			}
+			data.Schedules[0].Arrival = &flightpath.ScheduleDetail{
+				City: "Z",
+				Time: "2020-01-01T04:00:00Z",
+			}
+			shortestPath, err = controller.FindShortestFlightPath(ctx, data)
+			Expect(shortestPath).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.InvalidFlightSchedule))
+		})
+
+		It("should throw error if departure or arrival of any flight schedule provided does not have exactly one of timestamp, time and local time", func() {
+			data.TripPlan = &flightpath.TripDetail{
+				StartCity: "A",
+				EndCity:   "Z",
+			}
+			for _, departure := range []*flightpath.ScheduleDetail{
+				{City: "A"},
+				{City: "A", Timestamp: 1, Time: "2020-01-01T10:00:00Z"},
+				{City: "A", Time: "2020-01-01T10:00:00Z", LocalTime: "2020-01-01T10:00:00"},
+			} {
+				data.Schedules = []*flightpath.FlightDetail{
+					{
+						Departure: departure,
+						Arrival: &flightpath.ScheduleDetail{
+							City: "Z",
+							Time: "2020-01-01T12:00:00Z",
+						},
+					},
+				}
+				data.TimeZones = map[string]string{"A": "UTC"}
+				shortestPath, err := controller.FindShortestFlightPath(ctx, data)
+				Expect(shortestPath).Should(BeNil())
+				Expect(err).ShouldNot(BeNil())
+				Expect(err.Error()).To(Equal(errorconsts.InvalidFlightSchedule))
+			}
+			data.TimeZones = nil
+		})
+	})
+
+	Context("##flightpaths", func() {
+		controller := NewController(models.NewDaoWithCache(cache.NewMemory(0)))
+		data := flightpath.LazyJackRequest{
+			Schedules: []*flightpath.FlightDetail{
+				{
+					Departure: &flightpath.ScheduleDetail{
+						City:      "A",
+						Timestamp: 1,
+					},
+					Arrival: &flightpath.ScheduleDetail{
+						City:      "Z",
+						Timestamp: 10,
+					},
+				},
+				{
+					Departure: &flightpath.ScheduleDetail{
+						City:      "A",
+						Timestamp: 2,
+					},
+					Arrival: &flightpath.ScheduleDetail{
+						City:      "B",
+						Timestamp: 5,
+					},
+				},
+				{
+					Departure: &flightpath.ScheduleDetail{
+						City:      "B",
+						Timestamp: 6,
+					},
+					Arrival: &flightpath.ScheduleDetail{
+						City:      "Z",
+						Timestamp: 11,
+					},
+				},
+				{
+					Departure: &flightpath.ScheduleDetail{
+						City:      "A",
+						Timestamp: 3,
+					},
+					Arrival: &flightpath.ScheduleDetail{
+						City:      "Z",
+						Timestamp: 12,
+					},
+				},
+				{
+					Departure: &flightpath.ScheduleDetail{
+						City:      "B",
+						Timestamp: 12,
+					},
+					Arrival: &flightpath.ScheduleDetail{
+						City:      "Z",
+						Timestamp: 20,
+					},
+				},
+			},
+			TripPlan: &flightpath.TripDetail{
+				StartCity: "A",
+				EndCity:   "Z",
+			},
+		}
+
+		It("should return k shortest paths ranked by duration and then by number of stops", func() {
+			data.K = 3
+			flightPaths, err := controller.FindFlightPaths(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(len(flightPaths)).To(Equal(3))
+
+			// A(2) -> B(5) -> B(6) -> Z(11) takes as long as A(1) -> Z(10) & A(3) -> Z(12), but it has one stop
+			Expect(len(flightPaths[0])).To(Equal(2))
+			Expect(len(flightPaths[1])).To(Equal(2))
+			Expect(flightPaths[0][0].Timestamp).To(Equal(int64(1)))
+			Expect(flightPaths[1][0].Timestamp).To(Equal(int64(3)))
+			Expect(len(flightPaths[2])).To(Equal(4))
+			Expect(flightPaths[2][0].City).To(Equal("A"))
+			Expect(flightPaths[2][0].Timestamp).To(Equal(int64(2)))
+			Expect(flightPaths[2][1].City).To(Equal("B"))
+			Expect(flightPaths[2][1].Timestamp).To(Equal(int64(5)))
+			Expect(flightPaths[2][2].City).To(Equal("B"))
+			Expect(flightPaths[2][2].Timestamp).To(Equal(int64(6)))
+			Expect(flightPaths[2][3].City).To(Equal("Z"))
+			Expect(flightPaths[2][3].Timestamp).To(Equal(int64(11)))
+		})
+
+		It("should return all the available paths if there are less than k paths", func() {
+			data.K = 10
+			flightPaths, err := controller.FindFlightPaths(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(len(flightPaths)).To(Equal(4))
+
+			// A(2) -> B(5) -> B(12) -> Z(20) is the longest one
+			Expect(len(flightPaths[3])).To(Equal(4))
+			Expect(flightPaths[3][2].Timestamp).To(Equal(int64(12)))
+			Expect(flightPaths[3][3].Timestamp).To(Equal(int64(20)))
+		})
+
+		It("should not return paths which fly back to a city they have already visited with any engine", func() {
+			schedule := func(departureCity string, departure int64, arrivalCity string, arrival int64) *flightpath.FlightDetail {
+				return &flightpath.FlightDetail{
+					Departure: &flightpath.ScheduleDetail{City: departureCity, Timestamp: departure},
+					Arrival:   &flightpath.ScheduleDetail{City: arrivalCity, Timestamp: arrival},
+				}
+			}
+			loopData := flightpath.LazyJackRequest{
+				Schedules: []*flightpath.FlightDetail{
+					// return flight to the origin, A(1) -> B(2) -> A(4) -> Z(6) is a walk through the origin
+					schedule("A", 1, "B", 2),
+					schedule("B", 3, "A", 4),
+					schedule("A", 5, "Z", 6),
+					// loop through a connecting city, A(1) -> C(3) -> D(4) -> C(5) -> Z(8) is a walk through C
+					schedule("A", 1, "C", 3),
+					schedule("C", 3, "D", 4),
+					schedule("D", 4, "C", 5),
+					schedule("C", 6, "Z", 9),
+				},
+				TripPlan: &flightpath.TripDetail{StartCity: "A", EndCity: "Z"},
+				K:        3,
+			}
+
+			for _, engine := range []string{literals.Dijkstra, literals.CSA, literals.BruteForce} {
+				loopData.Engine = engine
+				itineraries, err := controller.findItineraries(ctx, loopData, loopData.K)
+				Expect(err).Should(BeNil())
+				Expect(len(itineraries)).To(Equal(2), engine)
+				Expect(len(itineraries[0].Legs)).To(Equal(1))
+				Expect(itineraries[0].Legs[0].Departure.Timestamp).To(Equal(int64(5)))
+				Expect(len(itineraries[1].Legs)).To(Equal(2))
+				Expect(itineraries[1].Legs[0].Arrival.City).To(Equal("C"))
+				Expect(itineraries[1].Legs[1].Departure.Timestamp).To(Equal(int64(6)))
+			}
+		})
+
+		It("should stop searching walks of dijkstra's algorithm and warn once the limit of walks is reached before k loopless paths are found", func() {
+			schedule := func(departureCity string, departure int64, arrivalCity string, arrival int64) *flightpath.FlightDetail {
+				return &flightpath.FlightDetail{
+					Departure: &flightpath.ScheduleDetail{City: departureCity, Timestamp: departure},
+					Arrival:   &flightpath.ScheduleDetail{City: arrivalCity, Timestamp: arrival},
+				}
+			}
+			// flights of zero duration flying back and forth between A and B at the same time make endless walks
+			// A(1) -> B(1) -> A(1) -> Z(2), while A(1) -> Z(2) is the only loopless path
+			loopData := flightpath.LazyJackRequest{
+				Schedules: []*flightpath.FlightDetail{
+					schedule("A", 1, "B", 1),
+					schedule("B", 1, "A", 1),
+					schedule("A", 1, "Z", 2),
+				},
+				TripPlan: &flightpath.TripDetail{StartCity: "A", EndCity: "Z"},
+				K:        2,
+			}
+
+			var logs bytes.Buffer
+			log.SetOutput(&logs)
+			defer log.SetOutput(os.Stderr)
+
+			// connection scan algorithm scans every flight once, so it does not have endless walks
+			itineraries, err := controller.findItineraries(ctx, loopData, loopData.K)
+			Expect(err).Should(BeNil())
+			Expect(len(itineraries)).To(Equal(1))
+			Expect(itineraries[0].Legs[0].Arrival.City).To(Equal("Z"))
+			Expect(logs.String()).To(ContainSubstring("searched " + strconv.Itoa(loopData.K*maxWalksPerPath) + " walks but found only 1 of 2 loopless paths"))
+			Expect(logs.String()).To(ContainSubstring(errWalksLimitReached.Error()))
+		})
+
+		It("should throw error if no path found between start and end city", func() {
+			data.K = 2
+			data.TripPlan = &flightpath.TripDetail{
+				StartCity: "B",
+				EndCity:   "A",
+			}
+			flightPaths, err := controller.FindFlightPaths(ctx, data)
+			Expect(flightPaths).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.NoFlightsAvailable))
+		})
+	})
+
+	Context("##objectives", func() {
+		controller := NewController(models.NewDaoWithCache(cache.NewMemory(0)))
+		flight := func(departure, arrival int64) *flightpath.FlightDetail {
+			return &flightpath.FlightDetail{
+				Departure: &flightpath.ScheduleDetail{
+					City:      "A",
+					Timestamp: departure,
+				},
+				Arrival: &flightpath.ScheduleDetail{
+					City:      "Z",
+					Timestamp: arrival,
+				},
+			}
+		}
+		data := flightpath.LazyJackRequest{
+			Schedules: []*flightpath.FlightDetail{
+				flight(1, 6),
+				flight(3, 6),
+				flight(7, 9),
+				flight(8, 12),
+				flight(10, 20),
+			},
+			TripPlan: &flightpath.TripDetail{
+				StartCity: "A",
+				EndCity:   "Z",
+			},
+		}
+
+		It("should return path with minimum duration by default", func() {
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(shortestPath[0].Timestamp).To(Equal(int64(7)))
+			Expect(shortestPath[1].Timestamp).To(Equal(int64(9)))
+		})
+
+		It("should return path with earliest arrival and then minimum duration", func() {
+			data.Objective = literals.EarliestArrival
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(shortestPath[0].Timestamp).To(Equal(int64(3)))
+			Expect(shortestPath[1].Timestamp).To(Equal(int64(6)))
+		})
+
+		It("should return path with latest departure", func() {
+			data.Objective = literals.LatestDeparture
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(shortestPath[0].Timestamp).To(Equal(int64(10)))
+			Expect(shortestPath[1].Timestamp).To(Equal(int64(20)))
+		})
+
+		It("should return path with latest departure that arrives by the deadline", func() {
+			data.Objective = literals.LatestDeparture
+			data.ArriveBy = 12
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(shortestPath[0].Timestamp).To(Equal(int64(8)))
+			Expect(shortestPath[1].Timestamp).To(Equal(int64(12)))
+		})
+
+		It("should rank k paths as per the objective", func() {
+			data.Objective = literals.EarliestArrival
+			data.ArriveBy = 0
+			data.K = 3
+			flightPaths, err := controller.FindFlightPaths(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(len(flightPaths)).To(Equal(3))
+			Expect(flightPaths[0][0].Timestamp).To(Equal(int64(3)))
+			Expect(flightPaths[1][0].Timestamp).To(Equal(int64(1)))
+			Expect(flightPaths[2][0].Timestamp).To(Equal(int64(7)))
+		})
+
+		It("should throw error if objective is invalid", func() {
+			data.Objective = "cheapest"
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
+			Expect(shortestPath).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.InvalidObjective))
+		})
+	})
+
+	Context("##connections", func() {
+		controller := NewController(models.NewDaoWithCache(cache.NewMemory(0)))
+		flight := func(departureCity string, departure int64, arrivalCity string, arrival int64) *flightpath.FlightDetail {
+			return &flightpath.FlightDetail{
+				Departure: &flightpath.ScheduleDetail{
+					City:      departureCity,
+					Timestamp: departure,
+				},
+				Arrival: &flightpath.ScheduleDetail{
+					City:      arrivalCity,
+					Timestamp: arrival,
+				},
+			}
+		}
+		data := flightpath.LazyJackRequest{
+			Schedules: []*flightpath.FlightDetail{
+				flight("A", 1, "B", 3),
+				flight("B", 3, "Z", 5),
+				flight("B", 5, "Z", 8),
+				flight("B", 20, "Z", 22),
+			},
+			TripPlan: &flightpath.TripDetail{
+				StartCity: "A",
+				EndCity:   "Z",
+			},
+		}
+
+		It("should allow connection at the same time as arrival by default", func() {
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(len(shortestPath)).To(Equal(3))
+			Expect(shortestPath[2].Timestamp).To(Equal(int64(5)))
+		})
+
+		It("should not return connections shorter than minimum connection time", func() {
+			data.MinConnectionTime = 1
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(len(shortestPath)).To(Equal(4))
+			Expect(shortestPath[2].Timestamp).To(Equal(int64(5)))
+			Expect(shortestPath[3].Timestamp).To(Equal(int64(8)))
+		})
+
+		It("should apply minimum connection time of the city over the request level value", func() {
+			data.MinConnectionTime = 1
+			data.ConnectionRules = map[string]*flightpath.ConnectionRule{
+				"B": {MinConnectionTime: 3},
+			}
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(len(shortestPath)).To(Equal(4))
+			Expect(shortestPath[2].Timestamp).To(Equal(int64(20)))
+			Expect(shortestPath[3].Timestamp).To(Equal(int64(22)))
+		})
+
+		It("should throw error if all the connections have longer layover than maximum layover", func() {
+			data.MinConnectionTime = 1
+			data.MaxLayover = 20
+			data.ConnectionRules = map[string]*flightpath.ConnectionRule{
+				"B": {MinConnectionTime: 3, MaxLayover: 10},
+			}
+			data.K = 5
+			flightPaths, err := controller.FindFlightPaths(ctx, data)
+			Expect(flightPaths).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.NoFlightsAvailable))
+		})
+
+		It("should throw error if minimum connection time is more than maximum layover", func() {
+			data.MinConnectionTime = 5
+			data.MaxLayover = 3
+			data.ConnectionRules = nil
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
+			Expect(shortestPath).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.InvalidConnectionRule))
+		})
+	})
+
+	Context("##flightidentity", func() {
+		controller := NewController(models.NewDaoWithCache(cache.NewMemory(0)))
+		flight := func(flightNumber string, departureCity string, departure int64, arrivalCity string, arrival int64) *flightpath.FlightDetail {
+			return &flightpath.FlightDetail{
+				ID:           "id-" + flightNumber,
+				FlightNumber: flightNumber,
+				Carrier:      "LT",
+				Aircraft:     "A320",
+				Departure: &flightpath.ScheduleDetail{
+					City:      departureCity,
+					Timestamp: departure,
+				},
+				Arrival: &flightpath.ScheduleDetail{
+					City:      arrivalCity,
+					Timestamp: arrival,
+				},
+			}
+		}
+		data := flightpath.LazyJackRequest{
+			Schedules: []*flightpath.FlightDetail{
+				flight("LT1", "A", 1, "B", 3),
+				flight("LT2", "B", 4, "Z", 6),
+				flight("LT2", "B", 4, "Z", 6),
+				flight("LT3", "B", 4, "Z", 6),
+			},
+			TripPlan: &flightpath.TripDetail{
+				StartCity: "A",
+				EndCity:   "Z",
+			},
+		}
+
+		It("should return identity of the flight used for each leg of the flight plan", func() {
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(len(shortestPath)).To(Equal(4))
+			Expect(shortestPath[0].Flight).Should(BeNil())
+			Expect(shortestPath[1].Flight).ShouldNot(BeNil())
+			Expect(shortestPath[1].Flight.ID).To(Equal("id-LT1"))
+			Expect(shortestPath[1].Flight.FlightNumber).To(Equal("LT1"))
+			Expect(shortestPath[1].Flight.Carrier).To(Equal("LT"))
+			Expect(shortestPath[1].Flight.Aircraft).To(Equal("A320"))
+			Expect(shortestPath[2].Flight).Should(BeNil())
+			Expect(shortestPath[3].Flight).ShouldNot(BeNil())
+		})
+
+		It("should treat identical flights as one and different flights at same time as separate flight plans", func() {
+			data.K = 5
+			flightPaths, err := controller.FindFlightPaths(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(len(flightPaths)).To(Equal(2))
+			flightNumbers := []string{flightPaths[0][3].Flight.FlightNumber, flightPaths[1][3].Flight.FlightNumber}
+			Expect(flightNumbers).To(ConsistOf("LT2", "LT3"))
+		})
+	})
+
+	Context("##itineraries", func() {
+		controller := NewController(models.NewDaoWithCache(cache.NewMemory(0)))
+		data := flightpath.LazyJackRequest{
+			Schedules: []*flightpath.FlightDetail{
+				{
+					FlightNumber: "LT1",
+					Departure: &flightpath.ScheduleDetail{
+						City:      "A",
+						Timestamp: 1,
+					},
+					Arrival: &flightpath.ScheduleDetail{
+						City:      "B",
+						Timestamp: 3,
+					},
+				},
+				{
+					FlightNumber: "LT2",
+					Departure: &flightpath.ScheduleDetail{
+						City:      "B",
+						Timestamp: 5,
+					},
+					Arrival: &flightpath.ScheduleDetail{
+						City:      "Z",
+						Timestamp: 8,
+					},
+				},
+			},
+			TripPlan: &flightpath.TripDetail{
+				StartCity: "A",
+				EndCity:   "Z",
+			},
+		}
+
+		It("should return itinerary with separate legs and totals", func() {
+			itineraries, err := controller.FindItineraries(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(len(itineraries)).To(Equal(1))
+
+			itinerary := itineraries[0]
+			Expect(itinerary.TotalDuration).To(Equal(int64(7)))
+			Expect(itinerary.InAirTime).To(Equal(int64(5)))
+			Expect(itinerary.LayoverTime).To(Equal(int64(2)))
+			Expect(itinerary.Stops).To(Equal(1))
+			Expect(len(itinerary.Legs)).To(Equal(2))
+
+			Expect(itinerary.Legs[0].Departure.City).To(Equal("A"))
+			Expect(itinerary.Legs[0].Departure.Timestamp).To(Equal(int64(1)))
+			Expect(itinerary.Legs[0].Arrival.City).To(Equal("B"))
+			Expect(itinerary.Legs[0].Arrival.Timestamp).To(Equal(int64(3)))
+			Expect(itinerary.Legs[0].Flight.FlightNumber).To(Equal("LT1"))
+			Expect(itinerary.Legs[0].FlightDuration).To(Equal(int64(2)))
+			Expect(itinerary.Legs[0].Layover).To(Equal(int64(2)))
+
+			Expect(itinerary.Legs[1].Departure.City).To(Equal("B"))
+			Expect(itinerary.Legs[1].Departure.Timestamp).To(Equal(int64(5)))
+			Expect(itinerary.Legs[1].Arrival.City).To(Equal("Z"))
+			Expect(itinerary.Legs[1].Arrival.Timestamp).To(Equal(int64(8)))
+			Expect(itinerary.Legs[1].Flight.FlightNumber).To(Equal("LT2"))
+			Expect(itinerary.Legs[1].FlightDuration).To(Equal(int64(3)))
+			Expect(itinerary.Legs[1].Layover).To(Equal(int64(0)))
+		})
+
+		It("should return same flight plan as the itinerary in flat format", func() {
+			itineraries, _ := controller.FindItineraries(ctx, data)
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(shortestPath).To(Equal(getFlightPlan(itineraries[0])))
+			Expect(len(shortestPath)).To(Equal(4))
+			Expect(shortestPath[2].City).To(Equal("B"))
+			Expect(shortestPath[2].Timestamp).To(Equal(int64(5)))
+		})
+
+		It("should throw error if no itinerary found between start and end city", func() {
+			data.TripPlan = &flightpath.TripDetail{
+				StartCity: "Z",
+				EndCity:   "A",
+			}
+			itineraries, err := controller.FindItineraries(ctx, data)
+			Expect(itineraries).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.NoFlightsAvailable))
+		})
+	})
+
+	Context("##pareto", func() {
+		controller := NewController(models.NewDaoWithCache(cache.NewMemory(0)))
+		flight := func(departureCity string, departure int64, arrivalCity string, arrival int64, fare float64) *flightpath.FlightDetail {
+			return &flightpath.FlightDetail{
+				Fare: &flightpath.Fare{
+					Amount:   fare,
+					Currency: "USD",
+				},
+				Departure: &flightpath.ScheduleDetail{
+					City:      departureCity,
+					Timestamp: departure,
+				},
+				Arrival: &flightpath.ScheduleDetail{
+					City:      arrivalCity,
+					Timestamp: arrival,
+				},
+			}
+		}
+		data := flightpath.LazyJackRequest{
+			Schedules: []*flightpath.FlightDetail{
+				flight("A", 1, "Z", 5, 300),
+				flight("A", 1, "B", 3, 50.25),
+				flight("B", 4, "Z", 10, 49.75),
+				flight("A", 2, "Z", 9, 200),
+				flight("A", 2, "Z", 12, 250),
+				flight("A", 1, "C", 2, 10),
+				flight("C", 2, "D", 3, 10),
+				flight("D", 3, "Z", 12, 10),
+			},
+			TripPlan: &flightpath.TripDetail{
+				StartCity: "A",
+				EndCity:   "Z",
+			},
+			Objective: literals.Pareto,
+		}
+
+		It("should return all the pareto optimal itineraries across duration, fare and stops ranked by duration", func() {
+			itineraries, err := controller.FindItineraries(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(len(itineraries)).To(Equal(4))
+
+			// fastest with fewest stops
+			Expect(itineraries[0].TotalDuration).To(Equal(int64(4)))
+			Expect(itineraries[0].TotalFare).To(Equal(&flightpath.Fare{Amount: 300, Currency: "USD"}))
+			Expect(itineraries[0].Stops).To(Equal(0))
+
+			Expect(itineraries[1].TotalDuration).To(Equal(int64(7)))
+			Expect(itineraries[1].TotalFare.Amount).To(Equal(float64(200)))
+
+			Expect(itineraries[2].TotalDuration).To(Equal(int64(9)))
+			Expect(itineraries[2].TotalFare.Amount).To(Equal(float64(100)))
+			Expect(itineraries[2].Stops).To(Equal(1))
+			Expect(itineraries[2].Legs[0].Fare.Amount).To(Equal(50.25))
+
+			// cheapest
+			Expect(itineraries[3].TotalDuration).To(Equal(int64(11)))
+			Expect(itineraries[3].TotalFare.Amount).To(Equal(float64(30)))
+			Expect(itineraries[3].Stops).To(Equal(2))
+		})
+
+		It("should limit pareto optimal itineraries to k", func() {
+			data.K = 2
+			itineraries, err := controller.FindItineraries(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(len(itineraries)).To(Equal(2))
+			Expect(itineraries[0].TotalDuration).To(Equal(int64(4)))
+			Expect(itineraries[1].TotalDuration).To(Equal(int64(7)))
+		})
+
+		It("should prefer cheaper path among paths with same duration", func() {
+			data.Objective = literals.MinDuration
+			data.K = 0
+			data.Schedules = append(data.Schedules, flight("A", 6, "Z", 10, 100))
+			itineraries, err := controller.FindItineraries(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(len(itineraries)).To(Equal(1))
+			Expect(itineraries[0].Legs[0].Departure.Timestamp).To(Equal(int64(6)))
+			Expect(itineraries[0].TotalFare.Amount).To(Equal(float64(100)))
+		})
+
+		It("should throw error if fares are in different currencies", func() {
+			data.Schedules = append(data.Schedules, &flightpath.FlightDetail{
+				Fare: &flightpath.Fare{
+					Amount:   10,
+					Currency: "EUR",
+				},
+				Departure: &flightpath.ScheduleDetail{
+					City:      "A",
+					Timestamp: 1,
+				},
+				Arrival: &flightpath.ScheduleDetail{
+					City:      "Z",
+					Timestamp: 2,
+				},
+			})
+			itineraries, err := controller.FindItineraries(ctx, data)
+			Expect(itineraries).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.InvalidFare))
+		})
+	})
+
+	Context("##timezones", func() {
+		controller := NewController(models.NewDaoWithCache(cache.NewMemory(0)))
+		data := flightpath.LazyJackRequest{
+			Schedules: []*flightpath.FlightDetail{
+				{
+					Departure: &flightpath.ScheduleDetail{
+						City:      "NYC",
+						LocalTime: "2019-06-01T18:00:00",
+					},
+					Arrival: &flightpath.ScheduleDetail{
+						City:      "LON",
+						LocalTime: "2019-06-02T06:00:00",
+					},
+				},
+				{
+					Departure: &flightpath.ScheduleDetail{
+						City: "LON",
+						Time: "2019-06-02T09:00:00+01:00",
+					},
+					Arrival: &flightpath.ScheduleDetail{
+						City: "DEL",
+						Time: "2019-06-02T21:30:00+05:30",
+					},
+				},
+			},
+			TripPlan: &flightpath.TripDetail{
+				StartCity: "NYC",
+				EndCity:   "DEL",
+			},
+			TimeZones: map[string]string{
+				"NYC": "America/New_York",
+				"LON": "Europe/London",
+			},
+		}
+
+		It("should convert local times & times with offset and return both utc and local times of each leg", func() {
+			itineraries, err := controller.FindItineraries(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(len(itineraries)).To(Equal(1))
+
+			legs := itineraries[0].Legs
+			Expect(len(legs)).To(Equal(2))
+			Expect(legs[0].Departure.UTCTime).To(Equal("2019-06-01T22:00:00Z"))
+			Expect(legs[0].Departure.LocalTime).To(Equal("2019-06-01T18:00:00-04:00"))
+			Expect(legs[0].Arrival.UTCTime).To(Equal("2019-06-02T05:00:00Z"))
+			Expect(legs[0].Arrival.LocalTime).To(Equal("2019-06-02T06:00:00+01:00"))
+			Expect(legs[0].FlightDuration).To(Equal(int64(7 * 60 * 60)))
+			Expect(legs[0].Layover).To(Equal(int64(3 * 60 * 60)))
+
+			// city without IANA time zone gets local time in offset of its time
+			Expect(legs[1].Arrival.UTCTime).To(Equal("2019-06-02T16:00:00Z"))
+			Expect(legs[1].Arrival.LocalTime).To(Equal("2019-06-02T21:30:00+05:30"))
+			Expect(legs[1].Arrival.Time).To(BeEmpty())
+		})
+
+		It("should return utc and local times in flat flight plan", func() {
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(len(shortestPath)).To(Equal(4))
+			Expect(shortestPath[0].Timestamp).To(Equal(int64(1559426400)))
+			Expect(shortestPath[0].LocalTime).To(Equal("2019-06-01T18:00:00-04:00"))
+			Expect(shortestPath[2].UTCTime).To(Equal("2019-06-02T08:00:00Z"))
+		})
+
+		It("should throw error if local time does not exist because of daylight saving time", func() {
+			data.Schedules[0].Departure.LocalTime = "2019-03-10T02:30:00"
+			itineraries, err := controller.FindItineraries(ctx, data)
+			Expect(itineraries).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.InvalidTime))
+		})
+
+		It("should throw error if local time is ambiguous because of daylight saving time", func() {
+			data.Schedules[0].Departure.LocalTime = "2019-11-03T01:30:00"
+			itineraries, err := controller.FindItineraries(ctx, data)
+			Expect(itineraries).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.InvalidTime))
+		})
+
+		It("should throw error if time zone of city with local time is not given", func() {
+			data.Schedules[0].Departure.LocalTime = "2019-06-01T18:00:00"
+			data.TimeZones = map[string]string{
+				"LON": "Europe/London",
+			}
+			itineraries, err := controller.FindItineraries(ctx, data)
+			Expect(itineraries).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.InvalidTimeZone))
+		})
+
+		It("should throw error if time zone is not a valid IANA time zone", func() {
+			data.TimeZones = map[string]string{
+				"NYC": "America/Gotham",
+				"LON": "Europe/London",
+			}
+			itineraries, err := controller.FindItineraries(ctx, data)
+			Expect(itineraries).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.InvalidTimeZone))
+		})
+	})
+
+	Context("##metroareas", func() {
+		dao := models.NewDaoWithCache(cache.NewMemory(0))
+		dao.CityCatalog, _ = catalog.Load("../../data/cities.csv")
+		controller := NewController(dao)
+		data := flightpath.LazyJackRequest{
+			Schedules: []*flightpath.FlightDetail{
+				{
+					Departure: &flightpath.ScheduleDetail{City: "JFK", Timestamp: 1},
+					Arrival:   &flightpath.ScheduleDetail{City: "LHR", Timestamp: 10},
+					Fare:      &flightpath.Fare{Amount: 100, Currency: "USD"},
+				},
+				{
+					Departure: &flightpath.ScheduleDetail{City: "EWR", Timestamp: 2},
+					Arrival:   &flightpath.ScheduleDetail{City: "LGW", Timestamp: 9},
+					Fare:      &flightpath.Fare{Amount: 300, Currency: "USD"},
+				},
+				{
+					Departure: &flightpath.ScheduleDetail{City: "LGA", Timestamp: 1},
+					Arrival:   &flightpath.ScheduleDetail{City: "CDG", Timestamp: 5},
+					Fare:      &flightpath.Fare{Amount: 50, Currency: "USD"},
+				},
+			},
+			TripPlan: &flightpath.TripDetail{
+				StartCity: "NYC",
+				EndCity:   "LON",
+			},
+		}
+
+		It("should find shortest flight path between any of the airports of metro areas", func() {
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(shortestPath[0].City).To(Equal("EWR"))
+			Expect(shortestPath[1].City).To(Equal("LGW"))
+		})
+
+		It("should rank flight paths from all the airports of metro areas together", func() {
+			data.K = 3
+			itineraries, err := controller.FindItineraries(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(len(itineraries)).To(Equal(2))
+			Expect(itineraries[0].Legs[0].Departure.City).To(Equal("EWR"))
+			Expect(itineraries[1].Legs[0].Departure.City).To(Equal("JFK"))
+		})
+
+		It("should compare pareto optimal flight paths to all the airports of destination metro area together", func() {
+			data.K = 0
+			data.Objective = literals.Pareto
+			itineraries, err := controller.FindItineraries(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(len(itineraries)).To(Equal(2))
+			Expect(itineraries[0].Legs[0].Arrival.City).To(Equal("LGW"))
+			Expect(itineraries[1].Legs[0].Arrival.City).To(Equal("LHR"))
+		})
+
+		It("should find flight path to an airport of the metro area only", func() {
+			data.Objective = ""
+			data.TripPlan = &flightpath.TripDetail{StartCity: "NYC", EndCity: "LHR"}
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(shortestPath[0].City).To(Equal("JFK"))
+		})
+
+		It("should apply connection rule of a metro area at all its airports unless an airport has its own rule", func() {
+			schedule := func(departureCity string, departure int64, arrivalCity string, arrival int64) *flightpath.FlightDetail {
+				return &flightpath.FlightDetail{
+					Departure: &flightpath.ScheduleDetail{City: departureCity, Timestamp: departure},
+					Arrival:   &flightpath.ScheduleDetail{City: arrivalCity, Timestamp: arrival},
+				}
+			}
+			connectingData := flightpath.LazyJackRequest{
+				Schedules: []*flightpath.FlightDetail{
+					schedule("LHR", 1, "JFK", 10),
+					schedule("JFK", 11, "SFO", 20),
+					schedule("JFK", 15, "SFO", 25),
+					schedule("CDG", 1, "LGA", 10),
+					schedule("LGA", 11, "SFO", 20),
+					schedule("LGA", 15, "SFO", 25),
+				},
+				TripPlan:        &flightpath.TripDetail{StartCity: "LHR", EndCity: "SFO"},
+				ConnectionRules: map[string]*flightpath.ConnectionRule{"NYC": {MinConnectionTime: 3}},
+			}
+
+			// connection at JFK has to wait for minimum connection time of NYC
+			itineraries, err := controller.FindItineraries(ctx, connectingData)
+			Expect(err).Should(BeNil())
+			Expect(itineraries[0].Legs[1].Departure.Timestamp).To(Equal(int64(15)))
+
+			// rule of the airport overrides rule of its metro area
+			connectingData.TripPlan = &flightpath.TripDetail{StartCity: "CDG", EndCity: "SFO"}
+			connectingData.ConnectionRules["LGA"] = &flightpath.ConnectionRule{MinConnectionTime: 1}
+			itineraries, err = controller.FindItineraries(ctx, connectingData)
+			Expect(err).Should(BeNil())
+			Expect(itineraries[0].Legs[1].Departure.Timestamp).To(Equal(int64(11)))
+		})
+
+		It("should throw error if trip starts and ends in same metro area", func() {
+			data.TripPlan = &flightpath.TripDetail{StartCity: "NYC", EndCity: "JFK"}
+			itineraries, err := controller.FindItineraries(ctx, data)
+			Expect(itineraries).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.SameStartEndCity))
+		})
+	})
+
+	Context("##multicity", func() {
+		controller := NewController(models.NewDaoWithCache(cache.NewMemory(0)))
+		schedule := func(departureCity string, departure int64, arrivalCity string, arrival int64) *flightpath.FlightDetail {
+			return &flightpath.FlightDetail{
+				Departure: &flightpath.ScheduleDetail{City: departureCity, Timestamp: departure},
+				Arrival:   &flightpath.ScheduleDetail{City: arrivalCity, Timestamp: arrival},
+			}
+		}
+		data := flightpath.LazyJackRequest{
+			Schedules: []*flightpath.FlightDetail{
+				schedule("A", 1, "B", 5),
+				schedule("A", 2, "B", 4),
+				schedule("B", 5, "C", 8),
+				schedule("B", 20, "C", 25),
+				schedule("B", 30, "C", 31),
+				schedule("C", 40, "D", 45),
+				schedule("D", 50, "A", 55),
+			},
+			TripPlan: &flightpath.TripDetail{
+				StartCity: "A",
+				EndCity:   "D",
+				Waypoints: []*flightpath.Waypoint{
+					{City: "B", MinStay: 10},
+					{City: "C"},
+				},
+			},
+		}
+
+		It("should find each segment after arrival of previous segment plus minimum stay and return segments separately", func() {
+			itineraries, err := controller.FindItineraries(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(len(itineraries)).To(Equal(1))
+
+			itinerary := itineraries[0]
+			Expect(len(itinerary.Segments)).To(Equal(3))
+			Expect(itinerary.Segments[0].Legs[0].Departure.Timestamp).To(Equal(int64(2)))
+			Expect(itinerary.Segments[1].Legs[0].Departure.Timestamp).To(Equal(int64(30)))
+			Expect(itinerary.Segments[1].TotalDuration).To(Equal(int64(1)))
+			Expect(itinerary.Segments[2].Legs[0].Departure.Timestamp).To(Equal(int64(40)))
+
+			Expect(len(itinerary.Legs)).To(Equal(3))
+			Expect(itinerary.TotalDuration).To(Equal(int64(43)))
+			Expect(itinerary.Legs[0].Layover).To(Equal(int64(26)))
+			Expect(itinerary.Legs[1].Layover).To(Equal(int64(9)))
+			Expect(itinerary.Stops).To(Equal(2))
+		})
+
+		It("should return flat flight plan of multi city trip", func() {
+			shortestPath, err := controller.FindShortestFlightPath(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(len(shortestPath)).To(Equal(6))
+			Expect(shortestPath[0]).To(Equal(flightpath.ScheduleDetail{City: "A", Timestamp: 2}))
+			Expect(shortestPath[5].City).To(Equal("D"))
+		})
+
+		It("should allow multi city trip to end where it started", func() {
+			roundTrip := data
+			roundTrip.TripPlan = &flightpath.TripDetail{
+				StartCity: "A",
+				EndCity:   "A",
+				Waypoints: []*flightpath.Waypoint{{City: "B"}, {City: "C"}, {City: "D"}},
+			}
+			itineraries, err := controller.FindItineraries(ctx, roundTrip)
+			Expect(err).Should(BeNil())
+			Expect(len(itineraries[0].Segments)).To(Equal(4))
+			Expect(itineraries[0].Legs[3].Arrival.City).To(Equal("A"))
+		})
+
+		It("should build the network once for all the segments of multi city trip", func() {
+			engine := registerCountingEngine()
+			defer delete(engines, engine.name)
+
+			multiCityData := data
+			multiCityData.Engine = engine.name
+			multiCityData.TripPlan = &flightpath.TripDetail{
+				StartCity: "A",
+				EndCity:   "A",
+				Waypoints: []*flightpath.Waypoint{{City: "B"}, {City: "C"}, {City: "D"}},
+			}
+			itineraries, err := controller.findItineraries(ctx, multiCityData, 1)
+			Expect(err).Should(BeNil())
+			Expect(len(itineraries[0].Segments)).To(Equal(4))
+			Expect(engine.networks).To(Equal(1))
+		})
+
+		It("should throw error if there is no flight after minimum stay at a waypoint", func() {
+			longStay := data
+			longStay.TripPlan = &flightpath.TripDetail{
+				StartCity: "A",
+				EndCity:   "D",
+				Waypoints: []*flightpath.Waypoint{{City: "B", MinStay: 100}, {City: "C"}},
+			}
+			itineraries, err := controller.FindItineraries(ctx, longStay)
+			Expect(itineraries).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.NoFlightsAvailable))
+		})
+
+		It("should throw error if waypoint is same as previous city or has negative minimum stay", func() {
+			invalidTrip := data
+			invalidTrip.TripPlan = &flightpath.TripDetail{
+				StartCity: "A",
+				EndCity:   "D",
+				Waypoints: []*flightpath.Waypoint{{City: "B"}, {City: "B"}},
+			}
+			_, err := controller.FindItineraries(ctx, invalidTrip)
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.InvalidWaypoint))
+
+			invalidTrip.TripPlan = &flightpath.TripDetail{
+				StartCity: "A",
+				EndCity:   "D",
+				Waypoints: []*flightpath.Waypoint{{City: "B", MinStay: -1}},
+			}
+			_, err = controller.FindItineraries(ctx, invalidTrip)
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.InvalidWaypoint))
+		})
+	})
+
+	Context("##roundtrip", func() {
+		controller := NewController(models.NewDaoWithCache(cache.NewMemory(0)))
+		schedule := func(departureCity string, departure int64, arrivalCity string, arrival int64) *flightpath.FlightDetail {
+			return &flightpath.FlightDetail{
+				Departure: &flightpath.ScheduleDetail{City: departureCity, Timestamp: departure},
+				Arrival:   &flightpath.ScheduleDetail{City: arrivalCity, Timestamp: arrival},
+			}
+		}
+		schedules := []*flightpath.FlightDetail{
+			schedule("A", 1, "B", 10),
+			schedule("A", 5, "B", 8),
+			schedule("A", 20, "B", 22),
+			schedule("B", 12, "A", 20),
+			schedule("B", 15, "A", 16),
+			schedule("B", 40, "A", 41),
+		}
+		tripPlan := &flightpath.TripDetail{
+			StartCity: "A",
+			EndCity:   "B",
+		}
+
+		It("should return best pair of outbound & return itineraries within the stay", func() {
+			itineraries, err := controller.FindItineraries(ctx, flightpath.LazyJackRequest{
+				Schedules: schedules,
+				TripPlan:  tripPlan,
+				RoundTrip: &flightpath.RoundTrip{MinStay: 5, MaxStay: 10},
+			})
+			Expect(err).Should(BeNil())
+			Expect(len(itineraries)).To(Equal(1))
+
+			itinerary := itineraries[0]
+			Expect(len(itinerary.Segments)).To(Equal(2))
+			Expect(itinerary.Segments[0].Legs[0].Departure).To(Equal(flightpath.ScheduleDetail{City: "A", Timestamp: 5}))
+			Expect(itinerary.Segments[1].Legs[0].Departure).To(Equal(flightpath.ScheduleDetail{City: "B", Timestamp: 15}))
+			Expect(itinerary.TotalDuration).To(Equal(int64(11)))
+			Expect(itinerary.InAirTime).To(Equal(int64(4)))
+			Expect(len(itinerary.Legs)).To(Equal(2))
+		})
+
+		It("should only consider outbound itineraries departing in the outbound window", func() {
+			itineraries, err := controller.FindItineraries(ctx, flightpath.LazyJackRequest{
+				Schedules: schedules,
+				TripPlan:  tripPlan,
+				RoundTrip: &flightpath.RoundTrip{OutboundWindow: &flightpath.TimeWindow{From: 10}},
+			})
+			Expect(err).Should(BeNil())
+			Expect(itineraries[0].Segments[0].Legs[0].Departure.Timestamp).To(Equal(int64(20)))
+			Expect(itineraries[0].Segments[1].Legs[0].Departure.Timestamp).To(Equal(int64(40)))
+
+			itineraries, err = controller.FindItineraries(ctx, flightpath.LazyJackRequest{
+				Schedules: schedules,
+				TripPlan:  tripPlan,
+				RoundTrip: &flightpath.RoundTrip{OutboundWindow: &flightpath.TimeWindow{From: 10}, MaxStay: 10},
+			})
+			Expect(itineraries).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.NoFlightsAvailable))
+		})
+
+		It("should only consider return itineraries departing in the return window", func() {
+			itineraries, err := controller.FindItineraries(ctx, flightpath.LazyJackRequest{
+				Schedules: schedules,
+				TripPlan:  tripPlan,
+				RoundTrip: &flightpath.RoundTrip{MinStay: 2, ReturnWindow: &flightpath.TimeWindow{To: 14}},
+			})
+			Expect(err).Should(BeNil())
+			Expect(itineraries[0].Segments[0].Legs[0].Departure.Timestamp).To(Equal(int64(5)))
+			Expect(itineraries[0].Segments[1].Legs[0].Departure.Timestamp).To(Equal(int64(12)))
+		})
+
+		It("should return best pair under the objective", func() {
+			itineraries, err := controller.FindItineraries(ctx, flightpath.LazyJackRequest{
+				Schedules: schedules,
+				TripPlan:  tripPlan,
+				Objective: literals.LatestDeparture,
+				RoundTrip: &flightpath.RoundTrip{MinStay: 5},
+			})
+			Expect(err).Should(BeNil())
+			Expect(itineraries[0].Segments[0].Legs[0].Departure.Timestamp).To(Equal(int64(20)))
+			Expect(itineraries[0].Segments[1].Legs[0].Departure.Timestamp).To(Equal(int64(40)))
+		})
+
+		It("should build the network of return itineraries once for all the outbound itineraries with any engine", func() {
+			data := flightpath.LazyJackRequest{
+				Schedules: schedules,
+				TripPlan:  tripPlan,
+				RoundTrip: &flightpath.RoundTrip{MinStay: 2},
+			}
+			engine := registerCountingEngine()
+			defer delete(engines, engine.name)
+
+			data.Engine = engine.name
+			expected, err := controller.findItineraries(ctx, data, 1)
+			Expect(err).Should(BeNil())
+			Expect(engine.networks).To(Equal(2))
+
+			for _, name := range []string{literals.CSA, literals.BruteForce} {
+				data.Engine = name
+				itineraries, err := controller.findItineraries(ctx, data, 1)
+				Expect(err).Should(BeNil())
+				Expect(itineraries).To(Equal(expected))
+			}
+		})
+
+		It("should throw error if round trip has invalid stay, windows or waypoints", func() {
+			for _, data := range []flightpath.LazyJackRequest{
+				{Schedules: schedules, TripPlan: tripPlan, RoundTrip: &flightpath.RoundTrip{MinStay: 10, MaxStay: 5}},
+				{Schedules: schedules, TripPlan: tripPlan, RoundTrip: &flightpath.RoundTrip{ReturnWindow: &flightpath.TimeWindow{From: 10, To: 5}}},
+				{
+					Schedules: schedules,
+					TripPlan:  &flightpath.TripDetail{StartCity: "A", EndCity: "B", Waypoints: []*flightpath.Waypoint{{City: "C"}}},
+					RoundTrip: &flightpath.RoundTrip{},
+				},
+			} {
+				itineraries, err := controller.FindItineraries(ctx, data)
+				Expect(itineraries).Should(BeNil())
+				Expect(err).ShouldNot(BeNil())
+				Expect(err.Error()).To(Equal(errorconsts.InvalidRoundTrip))
+			}
+		})
+	})
+
+	Context("##constraints", func() {
+		controller := NewController(models.NewDaoWithCache(cache.NewMemory(0)))
+		schedule := func(departureCity string, departure int64, arrivalCity string, arrival int64, carrier string) *flightpath.FlightDetail {
+			return &flightpath.FlightDetail{
+				Carrier:   carrier,
+				Departure: &flightpath.ScheduleDetail{City: departureCity, Timestamp: departure},
+				Arrival:   &flightpath.ScheduleDetail{City: arrivalCity, Timestamp: arrival},
+			}
+		}
+		schedules := []*flightpath.FlightDetail{
+			schedule("A", 11, "B", 12, "XX"),
+			schedule("B", 12, "C", 13, "XX"),
+			schedule("C", 13, "Z", 14, "ZZ"),
+			schedule("A", 10, "C", 13, "YY"),
+			schedule("A", 11, "Z", 20, "YY"),
+			schedule("A", 11, "Z", 20, "PP"),
+		}
+		tripPlan := &flightpath.TripDetail{
+			StartCity: "A",
+			EndCity:   "Z",
+		}
+		maxStops := func(stops int) *int {
+			return &stops
+		}
+		departures := func(itinerary *flightpath.Itinerary) []string {
+			cities := make([]string, 0, len(itinerary.Legs))
+			for _, l := range itinerary.Legs {
+				cities = append(cities, l.Departure.City)
+			}
+			return cities
+		}
+
+		It("should find fastest flight path without any constraint", func() {
+			itineraries, err := controller.FindItineraries(ctx, flightpath.LazyJackRequest{Schedules: schedules, TripPlan: tripPlan})
+			Expect(err).Should(BeNil())
+			Expect(departures(itineraries[0])).To(Equal([]string{"A", "B", "C"}))
+		})
+
+		It("should find best flight path within maximum stops even if a faster path with more stops reaches same connection first", func() {
+			itineraries, err := controller.FindItineraries(ctx, flightpath.LazyJackRequest{Schedules: schedules, TripPlan: tripPlan, MaxStops: maxStops(1)})
+			Expect(err).Should(BeNil())
+			Expect(departures(itineraries[0])).To(Equal([]string{"A", "C"}))
+			Expect(itineraries[0].Stops).To(Equal(1))
+		})
+
+		It("should find direct flights only if asked for direct flights or zero stops", func() {
+			for _, data := range []flightpath.LazyJackRequest{
+				{Schedules: schedules, TripPlan: tripPlan, DirectOnly: true},
+				{Schedules: schedules, TripPlan: tripPlan, MaxStops: maxStops(0)},
+			} {
+				itineraries, err := controller.FindItineraries(ctx, data)
+				Expect(err).Should(BeNil())
+				Expect(departures(itineraries[0])).To(Equal([]string{"A"}))
+			}
+		})
+
+		It("should not connect through excluded cities", func() {
+			itineraries, err := controller.FindItineraries(ctx, flightpath.LazyJackRequest{Schedules: schedules, TripPlan: tripPlan, ExcludedCities: []string{"B"}})
+			Expect(err).Should(BeNil())
+			Expect(departures(itineraries[0])).To(Equal([]string{"A", "C"}))
+
+			itineraries, err = controller.FindItineraries(ctx, flightpath.LazyJackRequest{Schedules: schedules, TripPlan: tripPlan, ExcludedCities: []string{"C"}})
+			Expect(err).Should(BeNil())
+			Expect(departures(itineraries[0])).To(Equal([]string{"A"}))
+		})
+
+		It("should not take flights of excluded carriers", func() {
+			itineraries, err := controller.FindItineraries(ctx, flightpath.LazyJackRequest{Schedules: schedules, TripPlan: tripPlan, ExcludedCarriers: []string{"xx"}})
+			Expect(err).Should(BeNil())
+			Expect(departures(itineraries[0])).To(Equal([]string{"A", "C"}))
+			Expect(itineraries[0].Legs[0].Flight.Carrier).To(Equal("YY"))
+		})
+
+		It("should prefer flights of preferred carriers over otherwise equal flights", func() {
+			itineraries, err := controller.FindItineraries(ctx, flightpath.LazyJackRequest{Schedules: schedules, TripPlan: tripPlan, DirectOnly: true, PreferredCarriers: []string{"PP"}})
+			Expect(err).Should(BeNil())
+			Expect(itineraries[0].Legs[0].Flight.Carrier).To(Equal("PP"))
+		})
+
+		It("should throw error if maximum stops is negative or a carrier is both excluded and preferred", func() {
+			for _, data := range []flightpath.LazyJackRequest{
+				{Schedules: schedules, TripPlan: tripPlan, MaxStops: maxStops(-1)},
+				{Schedules: schedules, TripPlan: tripPlan, ExcludedCarriers: []string{"XX"}, PreferredCarriers: []string{"xx"}},
+			} {
+				itineraries, err := controller.FindItineraries(ctx, data)
+				Expect(itineraries).Should(BeNil())
+				Expect(err).ShouldNot(BeNil())
+				Expect(err.Error()).To(Equal(errorconsts.InvalidSearchConstraint))
+			}
+		})
+	})
+
+	Context("##csa", func() {
+		controller := NewController(models.NewDaoWithCache(cache.NewMemory(0)))
+		tripPlan := &flightpath.TripDetail{
+			StartCity: "C0",
+			EndCity:   "C1",
+		}
+		maxStops := func(stops int) *int {
+			return &stops
+		}
+		requests := []flightpath.LazyJackRequest{
+			{},
+			{K: 5},
+			{K: 5, Objective: literals.EarliestArrival},
+			{K: 5, Objective: literals.LatestDeparture},
+			{K: 3, MinConnectionTime: 2, MaxLayover: 20},
+			{K: 3, MaxLayover: 15, ConnectionRules: map[string]*flightpath.ConnectionRule{"C2": {MinConnectionTime: 5, MaxLayover: 40}}},
+			{K: 4, MaxStops: maxStops(1)},
+			{K: 4, MaxStops: maxStops(2), MinConnectionTime: 1},
+			{K: 2, DirectOnly: true},
+			{K: 3, ExcludedCities: []string{"C2", "C3"}, ExcludedCarriers: []string{"XA"}, PreferredCarriers: []string{"XB"}},
+			{K: 3, PreferredTime: 30, ArriveBy: 150},
+		}
+
+		It("should find same itineraries as dijkstra's algorithm", func() {
+			for seed := int64(1); seed <= 20; seed++ {
+				for _, request := range requests {
+					request.TripPlan = tripPlan
+
+					request.Schedules = generateSchedules(seed, 8, 120, 200)
+					expected, expectedErr := controller.findItineraries(ctx, request, request.K)
+
+					request.Schedules = generateSchedules(seed, 8, 120, 200)
+					request.Engine = literals.CSA
+					itineraries, err := controller.findItineraries(ctx, request, request.K)
+
+					if expectedErr != nil {
+						Expect(err).To(Equal(expectedErr))
+						continue
+					}
+					Expect(err).Should(BeNil())
+					Expect(itineraries).To(Equal(expected))
+				}
+			}
+		})
+
+		It("should find same round trip and multi city itineraries as dijkstra's algorithm", func() {
+			for seed := int64(1); seed <= 20; seed++ {
+				for _, request := range []flightpath.LazyJackRequest{
+					{TripPlan: tripPlan, RoundTrip: &flightpath.RoundTrip{MinStay: 10}},
+					{TripPlan: &flightpath.TripDetail{StartCity: "C0", EndCity: "C1", Waypoints: []*flightpath.Waypoint{{City: "C2"}}}},
+				} {
+					request.Schedules = generateSchedules(seed, 6, 120, 300)
+					expected, expectedErr := controller.findItineraries(ctx, request, request.K)
+
+					request.Schedules = generateSchedules(seed, 6, 120, 300)
+					request.Engine = literals.CSA
+					itineraries, err := controller.findItineraries(ctx, request, request.K)
+
+					if expectedErr != nil {
+						Expect(err).To(Equal(expectedErr))
+						continue
+					}
+					Expect(err).Should(BeNil())
+					Expect(itineraries).To(Equal(expected))
+				}
+			}
+		})
+
+		It("should throw error for unknown engine or pareto objective with connection scan algorithm", func() {
+			for _, data := range []flightpath.LazyJackRequest{
+				{Schedules: generateSchedules(1, 8, 120, 200), TripPlan: tripPlan, Engine: "bellman-ford"},
+				{Schedules: generateSchedules(1, 8, 120, 200), TripPlan: tripPlan, Engine: literals.CSA, Objective: literals.Pareto},
+			} {
+				itineraries, err := controller.FindItineraries(ctx, data)
+				Expect(itineraries).Should(BeNil())
+				Expect(err).ShouldNot(BeNil())
+				Expect(err.Error()).To(Equal(errorconsts.InvalidEngine))
+			}
+		})
+	})
+
+	Context("##engines", func() {
+		controller := NewController(models.NewDaoWithCache(cache.NewMemory(0)))
+		tripPlan := &flightpath.TripDetail{
+			StartCity: "C0",
+			EndCity:   "C1",
+		}
+		maxStops := func(stops int) *int {
+			return &stops
+		}
+		requests := []flightpath.LazyJackRequest{
+			{TripPlan: tripPlan, K: 5},
+			{TripPlan: tripPlan, K: 5, Objective: literals.EarliestArrival},
+			{TripPlan: tripPlan, K: 5, Objective: literals.LatestDeparture},
+			{TripPlan: tripPlan, Objective: literals.Pareto},
+			{TripPlan: tripPlan, K: 2, Objective: literals.Pareto, MaxStops: maxStops(1)},
+			{TripPlan: tripPlan, K: 3, MinConnectionTime: 2, MaxLayover: 30, ExcludedCities: []string{"C2"}, PreferredCarriers: []string{"XA"}},
+			{TripPlan: tripPlan, RoundTrip: &flightpath.RoundTrip{MinStay: 5}},
+		}
+
+		It("should find same itineraries with every engine as brute force reference solver", func() {
+			for seed := int64(1); seed <= 10; seed++ {
+				for _, request := range requests {
+					request.Schedules = generateSchedules(seed, 5, 25, 150)
+					request.Engine = literals.BruteForce
+					expected, expectedErr := controller.findItineraries(ctx, request, request.K)
+
+					for name, engine := range engines {
+						if !engine.supports(request.Objective) {
+							continue
+						}
+						request.Schedules = generateSchedules(seed, 5, 25, 150)
+						request.Engine = name
+						itineraries, err := controller.findItineraries(ctx, request, request.K)
+						if expectedErr != nil {
+							Expect(err).To(Equal(expectedErr))
+							continue
+						}
+						Expect(err).Should(BeNil())
+						Expect(itineraries).To(Equal(expected))
+					}
+				}
+			}
+		})
+
+		It("should use default engine of the server if request does not ask for an engine", func() {
+			defaultEngine := constants.Env.DefaultEngine
+			defer func() {
+				constants.Env.DefaultEngine = defaultEngine
+			}()
+
+			constants.Env.DefaultEngine = literals.CSA
+			data := flightpath.LazyJackRequest{Schedules: generateSchedules(1, 5, 25, 150), TripPlan: tripPlan, Objective: literals.Pareto}
+			itineraries, err := controller.findItineraries(ctx, data, data.K)
+			Expect(itineraries).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.InvalidEngine))
+
+			data.Engine = literals.Dijkstra
+			itineraries, err = controller.findItineraries(ctx, data, data.K)
+			Expect(err).Should(BeNil())
+			Expect(itineraries).ShouldNot(BeEmpty())
+		})
+	})
+
+	Context("##batch", func() {
+		controller := NewController(models.NewDaoWithCache(cache.NewMemory(0)))
+		tripPlans := []*flightpath.TripDetail{
+			{StartCity: "C0", EndCity: "C1"},
+			{StartCity: "C2", EndCity: "C3"},
+			{StartCity: "C1", EndCity: "C0", Waypoints: []*flightpath.Waypoint{{City: "C4"}}},
+			{StartCity: "C5", EndCity: "C5"},
+			{StartCity: "C0", EndCity: "X"},
+		}
+
+		It("should find same itineraries for every trip plan of the batch as finding them one by one", func() {
+			for _, engine := range []string{literals.Dijkstra, literals.CSA} {
+				data := flightpath.BatchRequest{
+					LazyJackRequest: flightpath.LazyJackRequest{Schedules: generateSchedules(1, 6, 80, 200), K: 3, MinConnectionTime: 2, Engine: engine},
+					TripPlans:       tripPlans,
+				}
+				results, err := controller.FindBatchItineraries(ctx, data)
+				Expect(err).Should(BeNil())
+				Expect(len(results)).To(Equal(len(tripPlans)))
+
+				for i, tripPlan := range tripPlans {
+					Expect(results[i].TripPlan).To(Equal(tripPlan))
+
+					tripData := data.LazyJackRequest
+					tripData.TripPlan = tripPlan
+					itineraries, err := controller.findItineraries(ctx, tripData, tripData.K)
+					if err == nil {
+						err = validateTripPlan(tripPlan)
+					}
+					if err != nil {
+						Expect(results[i].Itineraries).Should(BeNil())
+						Expect(results[i].Error.Code).To(Equal(errorconsts.LTErrorMap[err.Error()].Code))
+						continue
+					}
+					Expect(results[i].Error).Should(BeNil())
+					Expect(results[i].Itineraries).To(Equal(itineraries))
+				}
+			}
+		})
+
+		It("should throw error for the whole batch if there are no trip plans, too many trip plans or schedules are invalid", func() {
+			maxTripPlans := constants.Env.BatchMaxTripPlans
+			defer func() {
+				constants.Env.BatchMaxTripPlans = maxTripPlans
+			}()
+			constants.Env.BatchMaxTripPlans = 2
+
+			for _, data := range []flightpath.BatchRequest{
+				{LazyJackRequest: flightpath.LazyJackRequest{Schedules: generateSchedules(1, 6, 80, 200)}},
+				{LazyJackRequest: flightpath.LazyJackRequest{Schedules: generateSchedules(1, 6, 80, 200)}, TripPlans: tripPlans},
+				{LazyJackRequest: flightpath.LazyJackRequest{Schedules: generateSchedules(1, 6, 80, 200), TripPlan: tripPlans[0]}, TripPlans: tripPlans[:1]},
+			} {
+				results, err := controller.FindBatchItineraries(ctx, data)
+				Expect(results).Should(BeNil())
+				Expect(err).ShouldNot(BeNil())
+				Expect(err.Error()).To(Equal(errorconsts.InvalidBatchRequest))
+			}
+
+			schedules := generateSchedules(1, 6, 80, 200)
+			schedules[0].Fare.Currency = ""
+			results, err := controller.FindBatchItineraries(ctx, flightpath.BatchRequest{LazyJackRequest: flightpath.LazyJackRequest{Schedules: schedules}, TripPlans: tripPlans[:1]})
+			Expect(results).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.InvalidFare))
+		})
+	})
+
+	Context("##explain", func() {
+		controller := NewController(models.NewDaoWithCache(cache.NewMemory(0)))
+		schedule := func(departureCity string, departure int64, arrivalCity string, arrival int64, fare float64) *flightpath.FlightDetail {
+			return &flightpath.FlightDetail{
+				Fare:      &flightpath.Fare{Amount: fare, Currency: "USD"},
+				Departure: &flightpath.ScheduleDetail{City: departureCity, Timestamp: departure},
+				Arrival:   &flightpath.ScheduleDetail{City: arrivalCity, Timestamp: arrival},
+			}
+		}
+		schedules := []*flightpath.FlightDetail{
+			schedule("A", 1, "B", 3, 10),
+			schedule("B", 2, "Z", 4, 10),
+			schedule("B", 4, "Z", 6, 10),
+			schedule("A", 1, "Z", 5, 50),
+			schedule("A", 2, "Z", 7, 10),
+			schedule("A", 4, "Z", 9, 20),
+			schedule("A", 6, "Z", 10, 60),
+		}
+		tripPlan := &flightpath.TripDetail{
+			StartCity: "A",
+			EndCity:   "Z",
+		}
+
+		It("should explain why other itineraries reaching end city were rejected", func() {
+			for _, engine := range []string{literals.Dijkstra, literals.CSA, literals.BruteForce} {
+				itineraries, explanation, err := controller.ExplainItineraries(ctx, flightpath.LazyJackRequest{Schedules: schedules, TripPlan: tripPlan, Engine: engine})
+				Expect(err).Should(BeNil())
+				Expect(itineraries[0].TotalDuration).To(Equal(int64(4)))
+				Expect(itineraries[0].TotalFare.Amount).To(Equal(float64(50)))
+
+				Expect(explanation.Engine).To(Equal(engine))
+				Expect(explanation.NodesExpanded).To(BeNumerically(">", 0))
+
+				reasons := make([]string, 0, len(explanation.Candidates))
+				for _, candidate := range explanation.Candidates {
+					Expect(candidate.Selected).To(Equal(candidate.RejectedReason == ""))
+					reasons = append(reasons, candidate.RejectedReason)
+				}
+				Expect(explanation.Candidates[0].Itinerary).To(Equal(itineraries[0]))
+				Expect(reasons).To(Equal([]string{"", "higher fare than itinerary 1", "longer duration than candidate 2", "higher fare than candidate 3", "more stops than candidate 4"}))
+			}
+		})
+
+		It("should explain rejected itineraries by the itinerary ranked just above them when many itineraries are selected", func() {
+			_, explanation, err := controller.ExplainItineraries(ctx, flightpath.LazyJackRequest{Schedules: schedules, TripPlan: tripPlan, K: 2})
+			Expect(err).Should(BeNil())
+
+			reasons := make([]string, 0, len(explanation.Candidates))
+			for _, candidate := range explanation.Candidates {
+				reasons = append(reasons, candidate.RejectedReason)
+			}
+			Expect(reasons).To(Equal([]string{"", "", "longer duration than itinerary 2", "higher fare than candidate 3", "more stops than candidate 4"}))
+		})
+
+		It("should count connecting flights which had already departed in every engine", func() {
+			for _, engine := range []string{literals.Dijkstra, literals.CSA, literals.BruteForce} {
+				_, explanation, err := controller.ExplainItineraries(ctx, flightpath.LazyJackRequest{Schedules: schedules, TripPlan: tripPlan, Engine: engine})
+				Expect(err).Should(BeNil())
+				Expect(explanation.ConnectionsPruned).To(Equal(1))
+			}
+		})
+
+		It("should explain rejected pareto itineraries by the itinerary dominating them", func() {
+			itineraries, explanation, err := controller.ExplainItineraries(ctx, flightpath.LazyJackRequest{Schedules: schedules, TripPlan: tripPlan, Objective: literals.Pareto})
+			Expect(err).Should(BeNil())
+			Expect(len(itineraries)).To(Equal(2))
+
+			reasons := make([]string, 0, len(explanation.Candidates))
+			for _, candidate := range explanation.Candidates {
+				reasons = append(reasons, candidate.RejectedReason)
+			}
+			Expect(reasons).To(ContainElement("dominated by itinerary 2 which is as fast, as cheap and with as few stops"))
+		})
+
+		It("should throw error while explaining multi city trips and round trips", func() {
+			for _, data := range []flightpath.LazyJackRequest{
+				{Schedules: schedules, TripPlan: &flightpath.TripDetail{StartCity: "A", EndCity: "Z", Waypoints: []*flightpath.Waypoint{{City: "B"}}}},
+				{Schedules: schedules, TripPlan: tripPlan, RoundTrip: &flightpath.RoundTrip{}},
+			} {
+				itineraries, explanation, err := controller.ExplainItineraries(ctx, data)
+				Expect(itineraries).Should(BeNil())
+				Expect(explanation).Should(BeNil())
+				Expect(err).ShouldNot(BeNil())
+				Expect(err.Error()).To(Equal(errorconsts.InvalidExplain))
+			}
+		})
+	})
+
+	Context("##deadlines", func() {
+		controller := NewController(models.NewDaoWithCache(cache.NewMemory(0)))
+		tripPlan := &flightpath.TripDetail{StartCity: "C0", EndCity: "C1"}
+		// brute force enumerates every path of a dense timetable, which does not complete in any reasonable time
+		pathological := flightpath.LazyJackRequest{Schedules: generateSchedules(1, 10, 2000, 7*24*60), TripPlan: tripPlan, Engine: literals.BruteForce}
+		maxSearchTime := constants.Env.MaxSearchTime
+		AfterEach(func() {
+			constants.Env.MaxSearchTime = maxSearchTime
+		})
+
+		It("should fail with search timeout once maximum search time has passed", func() {
+			constants.Env.MaxSearchTime = 50 * time.Millisecond
+
+			start := time.Now()
+			itineraries, err := controller.FindItineraries(ctx, pathological)
+			Expect(itineraries).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.SearchTimeout))
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+
+			_, err = controller.FindShortestFlightPath(ctx, pathological)
+			Expect(err.Error()).To(Equal(errorconsts.SearchTimeout))
+			_, _, err = controller.ExplainItineraries(ctx, pathological)
+			Expect(err.Error()).To(Equal(errorconsts.SearchTimeout))
+			batch := flightpath.BatchRequest{LazyJackRequest: pathological, TripPlans: []*flightpath.TripDetail{tripPlan}}
+			batch.TripPlan = nil
+			results, err := controller.FindBatchItineraries(ctx, batch)
+			Expect(err).Should(BeNil())
+			Expect(results[0].Error.Err).To(Equal(errorconsts.SearchTimeout))
+			Expect(errorconsts.LTErrorMap[errorconsts.SearchTimeout].Code).To(Equal(119))
+		})
+
+		It("should return results of the trip plans of the batch searched in time along with search timeout of the rest", func() {
+			constants.Env.MaxSearchTime = 200 * time.Millisecond
+			batchWorkers := constants.Env.BatchWorkers
+			constants.Env.BatchWorkers = 1
+			defer func() {
+				constants.Env.BatchWorkers = batchWorkers
+			}()
+
+			batch := flightpath.BatchRequest{LazyJackRequest: pathological}
+			batch.TripPlan = nil
+			batch.Schedules = append([]*flightpath.FlightDetail{{
+				Departure: &flightpath.ScheduleDetail{City: "FAST-A", Timestamp: 1},
+				Arrival:   &flightpath.ScheduleDetail{City: "FAST-Z", Timestamp: 10},
+			}}, pathological.Schedules...)
+			batch.TripPlans = []*flightpath.TripDetail{
+				{StartCity: "FAST-A", EndCity: "FAST-Z"},
+				tripPlan,
+				{StartCity: "C2", EndCity: "C3"},
+			}
+
+			results, err := controller.FindBatchItineraries(ctx, batch)
+			Expect(err).Should(BeNil())
+			Expect(len(results)).To(Equal(3))
+			Expect(results[0].Error).Should(BeNil())
+			Expect(len(results[0].Itineraries)).To(Equal(1))
+			for _, result := range results[1:] {
+				Expect(result.Itineraries).Should(BeNil())
+				Expect(result.Error.Err).To(Equal(errorconsts.SearchTimeout))
+				Expect(result.Error.Code).To(Equal(119))
+			}
+			Expect(results[2].TripPlan.StartCity).To(Equal("C2"))
+		})
+
+		It("should fail with search canceled once the request is canceled and search the next request again", func() {
+			constants.Env.MaxSearchTime = 0
+			canceledCtx, cancel := context.WithCancel(ctx)
+			time.AfterFunc(50*time.Millisecond, cancel)
+
+			for _, engine := range []string{literals.Dijkstra, literals.CSA, literals.BruteForce} {
+				data := pathological
+				data.Engine = engine
+				data.K = 1000000
+				_, err := controller.findItineraries(canceledCtx, data, data.K)
+				Expect(err).To(Equal(context.Canceled))
+			}
+			itineraries, err := controller.FindItineraries(canceledCtx, pathological)
+			Expect(itineraries).Should(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.SearchCanceled))
+
+			data := pathological
+			data.Schedules = generateSchedules(1, 6, 80, 200)
+			itineraries, err = controller.FindItineraries(ctx, data)
+			Expect(err).Should(BeNil())
+			Expect(itineraries).ShouldNot(BeEmpty())
+		})
 	})
 })
+
+// countingEngine is dijkstra's algorithm which counts the networks it builds
+type countingEngine struct {
+	dijkstraEngine
+	name     string
+	networks int
+}
+
+// registerCountingEngine registers a new counting engine in the registry of routing engines
+func registerCountingEngine() *countingEngine {
+	engine := &countingEngine{name: "counting"}
+	engines[engine.name] = engine
+	return engine
+}
+
+func (e *countingEngine) newNetwork(schedules []*flightpath.FlightDetail) (network, error) {
+	e.networks++
+	return e.dijkstraEngine.newNetwork(schedules)
+}
+
+// generateSchedules generates a synthetic timetable of flights between given number of cities departing within given time
+func generateSchedules(seed int64, cities, flights int, duration int64) []*flightpath.FlightDetail {
+	r := rand.New(rand.NewSource(seed))
+	carriers := []string{"XA", "XB", "XC"}
+
+	schedules := make([]*flightpath.FlightDetail, 0, flights)
+	for i := 0; i < flights; i++ {
+		departureCity := r.Intn(cities)
+		arrivalCity := (departureCity + 1 + r.Intn(cities-1)) % cities
+		departure := 1 + r.Int63n(duration)
+		schedules = append(schedules, &flightpath.FlightDetail{
+			FlightNumber: strconv.Itoa(i),
+			Carrier:      carriers[r.Intn(len(carriers))],
+			Fare:         &flightpath.Fare{Amount: float64(r.Intn(10) * 10), Currency: "USD"},
+			Departure:    &flightpath.ScheduleDetail{City: "C" + strconv.Itoa(departureCity), Timestamp: departure},
+			Arrival:      &flightpath.ScheduleDetail{City: "C" + strconv.Itoa(arrivalCity), Timestamp: departure + 1 + r.Int63n(30)},
+		})
+	}
+	return schedules
+}
+
+// benchmarkEngine benchmarks finding k shortest itineraries with given engine over a large synthetic timetable
+func benchmarkEngine(b *testing.B, engine string) {
+	controller := NewController(models.NewDaoWithCache(cache.NewMemory(0)))
+	data := flightpath.LazyJackRequest{
+		TripPlan:          &flightpath.TripDetail{StartCity: "C0", EndCity: "C1"},
+		K:                 5,
+		MinConnectionTime: 30,
+		Engine:            engine,
+	}
+	schedules := generateSchedules(1, 300, 20000, 7*24*60)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		data.Schedules = append([]*flightpath.FlightDetail{}, schedules...)
+		_, err := controller.findItineraries(context.Background(), data, data.K)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDijkstra(b *testing.B) {
+	benchmarkEngine(b, literals.Dijkstra)
+}
+
+func BenchmarkCSA(b *testing.B) {
+	benchmarkEngine(b, literals.CSA)
+}
@@ -0,0 +1,366 @@
+package flightpath
+
+import (
+	"container/heap"
+	"context"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
+	"sort"
+	"strconv"
+)
+
+// connection is a single flight of the timetable between two cities
+type connection struct {
+	departureCity int // index of the departure city in the timetable
+	arrivalCity   int // index of the arrival city in the timetable
+	edge          edge
+}
+
+// timetable is the list of all the flights sorted by their departure, which is scanned by the connection scan algorithm
+// unlike graph, it has only one entry per flight and cities are identified by their index instead of their names
+type timetable struct {
+	cityIndexes map[string]int
+	cities      []string
+	connections []connection
+}
+
+// csaEngine scans timetable of the flight schedules using connection scan algorithm
+type csaEngine struct{}
+
+// supports tells if the engine can search under the objective
+// connection scan algorithm keeps k best paths of every arrival, which do not cover all the pareto optimal paths
+func (csaEngine) supports(objective string) bool {
+	return objective != literals.Pareto
+}
+
+// newNetwork converts the schedules into timetable
+func (csaEngine) newNetwork(schedules []*flightpath.FlightDetail) (network, error) {
+	scheduleTimetable, err := generateTimetableOfSchedules(schedules)
+	if err != nil {
+		return nil, err
+	}
+	return scheduleTimetable, nil
+}
+
+// findPaths finds the paths by scanning the timetable as per the options
+func (t *timetable) findPaths(ctx context.Context, sources, destinations []string, options searchOptions) ([]directPath, error) {
+	// execute connection scan algorithm to get array of paths from source to destination
+	paths, err := t.getShortestPaths(ctx, sources, destinations, options)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info(literals.LazyJack, "successfully applied connection scan algorithm and found "+strconv.Itoa(len(paths))+" paths", nil)
+	return paths, nil
+}
+
+// generateTimetableOfSchedules converts flight schedules into timetable of connections sorted by their departure
+// connections departing at same time are sorted by their arrival, so that a connection arriving at the departure time is scanned first
+func generateTimetableOfSchedules(schedules []*flightpath.FlightDetail) (*timetable, error) {
+	uniqueSchedules, err := getUniqueSchedules(schedules)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &timetable{
+		cityIndexes: make(map[string]int),
+		connections: make([]connection, 0, len(uniqueSchedules)),
+	}
+	for _, schedule := range uniqueSchedules {
+		t.connections = append(t.connections, connection{
+			departureCity: t.getCityIndex(schedule.Departure.City),
+			arrivalCity:   t.getCityIndex(schedule.Arrival.City),
+			edge: edge{
+				Schedule:              *schedule.Arrival,
+				Duration:              schedule.Arrival.Timestamp - schedule.Departure.Timestamp,
+				OriginFlightTimestamp: schedule.Departure.Timestamp,
+				Flight:                getFlightIdentity(schedule),
+				Fare:                  schedule.Fare,
+			},
+		})
+	}
+
+	sort.SliceStable(t.connections, func(i, j int) bool {
+		if t.connections[i].edge.OriginFlightTimestamp != t.connections[j].edge.OriginFlightTimestamp {
+			return t.connections[i].edge.OriginFlightTimestamp < t.connections[j].edge.OriginFlightTimestamp
+		}
+		return t.connections[i].edge.Schedule.Timestamp < t.connections[j].edge.Schedule.Timestamp
+	})
+	return t, nil
+}
+
+// getCityIndex gets index of the city, city is added to the timetable if it is not there
+func (t *timetable) getCityIndex(city string) int {
+	index, ok := t.cityIndexes[city]
+	if !ok {
+		index = len(t.cities)
+		t.cityIndexes[city] = index
+		t.cities = append(t.cities, city)
+	}
+	return index
+}
+
+// getCitySet gets a set of indexes of given cities, cities which are not in the timetable are ignored
+func (t *timetable) getCitySet(cities []string) []bool {
+	citySet := make([]bool, len(t.cities))
+	for _, city := range cities {
+		if index, ok := t.cityIndexes[city]; ok {
+			citySet[index] = true
+		}
+	}
+	return citySet
+}
+
+// getShortestPaths gets up to k shortest paths from any of the source cities to any of the destination cities
+// using connection scan algorithm, paths are same as the paths found by dijkstra's algorithm over graph of same schedules
+// connections are scanned once in order of their departure, as a connection can only be followed by connections departing after it
+// while scanning, up to k best paths to every arrival at a city are kept, which are the paths a connecting flight can extend
+// scan stops once the context is done
+func (t *timetable) getShortestPaths(ctx context.Context, sources, destinations []string, options searchOptions) ([]directPath, error) {
+	k := options.k
+	if options.allArrivals {
+		k = 1
+	}
+
+	// paths with different number of flights are kept in separate layers when number of flights is limited, same as dijkstra's algorithm
+	// paths in the last layer have taken maximum number of flights, so they cannot take any connecting flight
+	layers, connectingLayers := 1, 1
+	if options.maxFlights != 0 {
+		layers, connectingLayers = options.maxFlights+1, options.maxFlights
+	}
+	stops := make([]*stop, len(t.cities)*layers)
+	getStop := func(city int, p directPath) *stop {
+		index := city * layers
+		if options.maxFlights != 0 {
+			index += p.flights()
+		}
+		if stops[index] == nil {
+			stops[index] = newStop()
+		}
+		return stops[index]
+	}
+
+	isSource := t.getCitySet(sources)
+	isDestination := t.getCitySet(destinations)
+
+	// k best paths to the destination found so far, a path can be pruned if it is not better than all of them
+	// as every path made by taking more flights from a path ranks after it
+	var targets []directPath
+
+	// connections departing before the earliest departure cannot be part of any path, as every path starts after it
+	start := sort.Search(len(t.connections), func(i int) bool {
+		return t.connections[i].edge.OriginFlightTimestamp >= options.departAfter
+	})
+	for _, c := range t.connections[start:] {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// paths do not fly any further once they have reached the destination
+		if isDestination[c.departureCity] {
+			continue
+		}
+
+		departureCity := t.cities[c.departureCity]
+		departure := c.edge.OriginFlightTimestamp
+		minConnectionTime := options.getMinConnectionTime(departureCity)
+		maxLayover := options.getMaxLayover(departureCity)
+
+		for layer := 0; layer < connectingLayers; layer++ {
+			// paths which can take this connection i.e. paths arriving at the departure city within connection time
+			// along with a new path if the departure city is a source
+			paths := make([]directPath, 0, k+1)
+			if s := stops[c.departureCity*layers+layer]; s != nil {
+				paths = append(paths, s.getConnectingPaths(departure, minConnectionTime, maxLayover, k, options)...)
+				if options.trace != nil {
+					options.trace.pruneConnections(s.countDepartedPaths(departure))
+				}
+			}
+			if layer == 0 && isSource[c.departureCity] {
+				paths = append(paths, directPath{node: flightpath.ScheduleDetail{City: departureCity}})
+			}
+			if len(paths) != 0 {
+				options.trace.expandNode()
+			}
+
+			for _, p := range paths {
+				if !p.canTakeFlight(departure, c.edge.Flight, options) {
+					continue
+				}
+				nextPath := p.takeFlight(c.edge, options)
+				if isDestination[c.arrivalCity] {
+					options.trace.addCandidate(nextPath)
+				}
+				if !options.allArrivals && len(targets) == k && !isBetterPath(nextPath, targets[k-1]) {
+					continue
+				}
+
+				switch {
+				case isDestination[c.arrivalCity] && !options.allArrivals:
+					targets = mergePaths(targets, []directPath{nextPath}, k, isBetterPath)
+				case !isDestination[c.arrivalCity] && options.maxFlights != 0 && nextPath.flights() >= options.maxFlights:
+					// paths which have taken maximum number of flights are only kept if they have reached the destination
+				default:
+					getStop(c.arrivalCity, nextPath).addPath(nextPath, k)
+				}
+			}
+		}
+	}
+
+	if !options.allArrivals {
+		return targets, nil
+	}
+
+	// collect the paths to every arrival at the destination cities
+	shortestPaths := make([]directPath, 0)
+	for city, ok := range isDestination {
+		if !ok {
+			continue
+		}
+		for layer := 0; layer < layers; layer++ {
+			if s := stops[city*layers+layer]; s != nil {
+				for _, event := range s.events {
+					shortestPaths = append(shortestPaths, event.paths...)
+				}
+			}
+		}
+	}
+	sort.Sort(path(shortestPaths))
+	return shortestPaths, nil
+}
+
+// arrivalEvent is an arrival at a city at a time along with up to k best paths arriving there
+type arrivalEvent struct {
+	timestamp int64
+	paths     []directPath
+}
+
+// stop is the state of a city while scanning the connections
+// arrivals become available to connecting flights in order of their timestamp once minimum connection time has passed
+type stop struct {
+	events    map[int64]*arrivalEvent
+	pending   timestamps      // timestamps of the arrivals which are not yet available to connecting flights
+	available []*arrivalEvent // arrivals available to connecting flights in order of their timestamp
+	first     int             // index of the first available arrival within maximum layover
+	best      []directPath    // best paths of all the available arrivals, used when there is no maximum layover
+}
+
+// newStop creates a new stop
+func newStop() *stop {
+	return &stop{events: make(map[int64]*arrivalEvent)}
+}
+
+// addPath adds a path arriving at the stop, only k best paths are kept for each arrival
+func (s *stop) addPath(p directPath, k int) {
+	event, ok := s.events[p.node.Timestamp]
+	if !ok {
+		event = &arrivalEvent{timestamp: p.node.Timestamp}
+		s.events[p.node.Timestamp] = event
+		heap.Push(&s.pending, p.node.Timestamp)
+	}
+	event.paths = mergePaths(event.paths, []directPath{p}, k, isBetterPath)
+}
+
+// getConnectingPaths gets up to k best paths which can connect to a flight departing at given time
+// connections are scanned in order of their departure, so arrivals once available stay available until maximum layover has passed
+func (s *stop) getConnectingPaths(departure, minConnectionTime, maxLayover int64, k int, options searchOptions) []directPath {
+	isBetter := func(first, second directPath) bool {
+		return isBetterConnection(first, second, options)
+	}
+
+	for len(s.pending) > 0 && s.pending[0] <= departure-minConnectionTime {
+		event := s.events[heap.Pop(&s.pending).(int64)]
+		s.available = append(s.available, event)
+		if maxLayover == 0 {
+			s.best = mergePaths(s.best, event.paths, k, isBetter)
+		}
+	}
+	if maxLayover == 0 {
+		return s.best
+	}
+
+	for s.first < len(s.available) && s.available[s.first].timestamp < departure-maxLayover {
+		s.first++
+	}
+	var paths []directPath
+	for _, event := range s.available[s.first:] {
+		paths = mergePaths(paths, event.paths, k, isBetter)
+	}
+	return paths
+}
+
+// countDepartedPaths counts the paths arriving at the stop after a flight departing at given time, which cannot connect to it
+// as it had already departed, same as the connections pruned by dijkstra's algorithm
+func (s *stop) countDepartedPaths(departure int64) int {
+	count := 0
+	for _, timestamp := range s.pending {
+		if timestamp > departure {
+			count += len(s.events[timestamp].paths)
+		}
+	}
+	return count
+}
+
+// isBetterConnection tells if first path ranks before second path once both of them have waited for the same connecting flight
+// paths arriving at different times are ranked as if both of them arrived at the later arrival, as taking the same flight
+// adds same duration to both of them after that, which keeps their ranking same for every connecting flight
+func isBetterConnection(first, second directPath, options searchOptions) bool {
+	timestamp := maxTimestamp(first.node.Timestamp, second.node.Timestamp)
+	return isBetterPath(waitUntil(first, timestamp, options), waitUntil(second, timestamp, options))
+}
+
+// waitUntil gets the path which waits at its last city until given time
+func waitUntil(p directPath, timestamp int64, options searchOptions) directPath {
+	p.duration += timestamp - p.node.Timestamp
+	p.node.Timestamp = timestamp
+	p.cost = options.cost(p)
+	return p
+}
+
+// mergePaths merges two ranked lists of paths into a ranked list of up to k best paths
+func mergePaths(first, second []directPath, k int, isBetter func(first, second directPath) bool) []directPath {
+	merged := make([]directPath, 0, k)
+	i, j := 0, 0
+	for len(merged) < k && (i < len(first) || j < len(second)) {
+		if j == len(second) || (i < len(first) && !isBetter(second[j], first[i])) {
+			merged = append(merged, first[i])
+			i++
+		} else {
+			merged = append(merged, second[j])
+			j++
+		}
+	}
+	return merged
+}
+
+// timestamps is a min heap of timestamps
+type timestamps []int64
+
+// Len gets number of timestamps
+func (t timestamps) Len() int {
+	return len(t)
+}
+
+// Less tells if a timestamp is before another timestamp
+func (t timestamps) Less(i, j int) bool {
+	return t[i] < t[j]
+}
+
+// Swap swaps two timestamps
+func (t timestamps) Swap(i, j int) {
+	t[i], t[j] = t[j], t[i]
+}
+
+// Push adds a timestamp to the heap
+func (t *timestamps) Push(x interface{}) {
+	*t = append(*t, x.(int64))
+}
+
+// Pop removes the last timestamp from the heap
+func (t *timestamps) Pop() interface{} {
+	old := *t
+	n := len(old)
+	x := old[n-1]
+	*t = old[0 : n-1]
+	return x
+}
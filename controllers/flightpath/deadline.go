@@ -0,0 +1,28 @@
+package flightpath
+
+import (
+	"context"
+	"errors"
+	"github.com/somprabhsharma/the-lazy-traveler/constants"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/errorconsts"
+)
+
+// withSearchDeadline limits the context to the maximum search time configured in the environment, zero means there is no limit
+func withSearchDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if constants.Env.MaxSearchTime <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, constants.Env.MaxSearchTime)
+}
+
+// getSearchError converts error of a done context into the error of the search, every other error is returned as it is
+// i.e. search timeout once maximum search time has passed and search canceled once the request is closed
+func getSearchError(err error) error {
+	switch err {
+	case context.DeadlineExceeded:
+		return errors.New(errorconsts.SearchTimeout)
+	case context.Canceled:
+		return errors.New(errorconsts.SearchCanceled)
+	}
+	return err
+}
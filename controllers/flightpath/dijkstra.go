@@ -2,14 +2,64 @@ package flightpath
 
 import (
 	"container/heap"
+	"context"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
 	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
 	"strconv"
 )
 
 // directPath is a direct path struct between two nodes with duration
 type directPath struct {
+	cost     int64
 	duration int64
-	nodes    []flightpath.ScheduleDetail
+	fare     int64                     // total fare of the path in minor units
+	node     flightpath.ScheduleDetail // last node of the path
+	legs     []leg
+
+	// nonPreferredFlights is number of flights which are not operated by preferred carriers
+	nonPreferredFlights int
+}
+
+// leg is a single flight taken in a path
+type leg struct {
+	departure flightpath.ScheduleDetail
+	arrival   flightpath.ScheduleDetail
+	flight    *flightpath.FlightIdentity
+	fare      *flightpath.Fare
+}
+
+// key gets a key which uniquely identifies the leg among the legs of other paths
+func (l leg) key() string {
+	return getScheduleKey(l.departure, l.arrival, l.flight, l.fare)
+}
+
+// flights gets number of flights taken in the path
+func (p directPath) flights() int {
+	return len(p.legs)
+}
+
+// isLoopless tells if the path does not visit any city more than once, including the city where it starts
+func (p directPath) isLoopless() bool {
+	if len(p.legs) == 0 {
+		return true
+	}
+	visited := map[string]bool{p.legs[0].departure.City: true}
+	for _, l := range p.legs {
+		if visited[l.arrival.City] {
+			return false
+		}
+		visited[l.arrival.City] = true
+	}
+	return true
+}
+
+// departureTimestamp gets departure time of the first flight of the path, it is 0 if path does not have any flight yet
+func (p directPath) departureTimestamp() int64 {
+	if len(p.legs) == 0 {
+		return 0
+	}
+	return p.legs[0].departure.Timestamp
 }
 
 // define a type path, that is array of individual direct paths
@@ -22,7 +72,58 @@ func (p path) Len() int {
 
 // Less compares two paths values and tells if a path is less than another path
 func (p path) Less(i, j int) bool {
-	return p[i].duration < p[j].duration
+	return isBetterPath(p[i], p[j])
+}
+
+// isBetterPath tells if first path ranks before second path
+// paths are compared by the cost of the objective and then by their duration
+// paths with same duration are compared by their fare i.e. cheaper path is preferred
+// paths with same duration & fare are compared by number of flights i.e. path with less stops is preferred
+// paths with same duration, fare & stops are compared by number of flights not operated by preferred carriers
+// and then by their departure time i.e. earlier path is preferred
+// paths which are still equal are compared by their flights, so that every search engine ranks them in the same order
+func isBetterPath(first, second directPath) bool {
+	if first.cost != second.cost {
+		return first.cost < second.cost
+	}
+	if first.duration != second.duration {
+		return first.duration < second.duration
+	}
+	if first.fare != second.fare {
+		return first.fare < second.fare
+	}
+	if first.flights() != second.flights() {
+		return first.flights() < second.flights()
+	}
+	if first.nonPreferredFlights != second.nonPreferredFlights {
+		return first.nonPreferredFlights < second.nonPreferredFlights
+	}
+	if first.departureTimestamp() != second.departureTimestamp() {
+		return first.departureTimestamp() < second.departureTimestamp()
+	}
+	return compareLegs(first.legs, second.legs) < 0
+}
+
+// compareLegs compares legs of two paths one by one and returns a negative number, zero or a positive number
+// as first legs are less than, equal to or more than second legs
+func compareLegs(first, second []leg) int {
+	for i := 0; i < len(first) && i < len(second); i++ {
+		if c := compareStrings(first[i].key(), second[i].key()); c != 0 {
+			return c
+		}
+	}
+	return len(first) - len(second)
+}
+
+// compareStrings compares two strings and returns -1, 0 or +1
+func compareStrings(a, b string) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
 }
 
 // Swap swaps two paths
@@ -73,7 +174,9 @@ type edge struct {
 	Schedule              flightpath.ScheduleDetail
 	Duration              int64
 	OriginFlightTimestamp int64
-	Reverse               bool //reverse flag to ignore reverse directional edges
+	Flight                *flightpath.FlightIdentity // identity of the flight, nil if flight schedule does not have any
+	Fare                  *flightpath.Fare           // fare of the flight, nil if flight schedule does not have any
+	Reverse               bool                       //reverse flag to ignore reverse directional edges
 }
 
 // graph is a graph data structure to store the various schedules from given source i.e. neighbouring nodes of a vertex
@@ -87,9 +190,9 @@ func newGraph() *graph {
 }
 
 // addEdge adds an edge to the graph
-func (g *graph) addEdge(source, destination flightpath.ScheduleDetail, duration int64) {
-	g.Schedules[source.City] = append(g.Schedules[source.City], edge{Schedule: destination, Duration: duration, OriginFlightTimestamp: source.Timestamp, Reverse: false})
-	g.Schedules[destination.City] = append(g.Schedules[destination.City], edge{Schedule: source, Duration: duration, OriginFlightTimestamp: destination.Timestamp, Reverse: true})
+func (g *graph) addEdge(source, destination flightpath.ScheduleDetail, duration int64, flight *flightpath.FlightIdentity, fare *flightpath.Fare) {
+	g.Schedules[source.City] = append(g.Schedules[source.City], edge{Schedule: destination, Duration: duration, OriginFlightTimestamp: source.Timestamp, Flight: flight, Fare: fare, Reverse: false})
+	g.Schedules[destination.City] = append(g.Schedules[destination.City], edge{Schedule: source, Duration: duration, OriginFlightTimestamp: destination.Timestamp, Flight: flight, Fare: fare, Reverse: true})
 }
 
 // getEdges gets all the edges of given node
@@ -97,97 +200,243 @@ func (g *graph) getEdges(node string) []edge {
 	return g.Schedules[node]
 }
 
-// getShortestPaths gets the shortest path between source and destination
-func (g *graph) getShortestPaths(source, destination flightpath.ScheduleDetail) (int64, map[int64][][]flightpath.ScheduleDetail) {
-	// create a heap tree starting with the source city as first node
+// dijkstraEngine searches time expanded graph of the flight schedules using dijkstra's algorithm
+type dijkstraEngine struct{}
+
+// supports tells if the engine can search under the objective, dijkstra's algorithm supports every objective
+func (dijkstraEngine) supports(objective string) bool {
+	return true
+}
+
+// newNetwork converts the schedules into graph
+func (dijkstraEngine) newNetwork(schedules []*flightpath.FlightDetail) (network, error) {
+	scheduleGraph, err := generateGraphOfSchedules(schedules)
+	if err != nil {
+		return nil, err
+	}
+	return scheduleGraph, nil
+}
+
+// findPaths finds the paths over the graph as per the options
+func (g *graph) findPaths(ctx context.Context, sources, destinations []string, options searchOptions) ([]directPath, error) {
+	switch {
+	case options.allArrivals:
+		// execute dijkstra's algorithm to get the shortest path to every arrival at the destination
+		paths, err := g.getShortestPathsByArrival(ctx, sources, destinations, options)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info(literals.LazyJack, "successfully applied dijkstra's algorithm and found paths to "+strconv.Itoa(len(paths))+" arrivals", nil)
+		return paths, nil
+	case options.objective == literals.Pareto:
+		// execute multi criteria search to get pareto optimal paths from source to destination
+		paths, err := g.getParetoPaths(ctx, sources, destinations, options)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info(literals.LazyJack, "successfully applied pareto search and found "+strconv.Itoa(len(paths))+" paths", nil)
+		return paths, nil
+	default:
+		// execute dijkstra's algorithm to get array of paths from source to destination
+		paths, err := g.getShortestPaths(ctx, sources, destinations, options)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info(literals.LazyJack, "successfully applied dijkstra's algorithm and found "+strconv.Itoa(len(paths))+" paths", nil)
+		return paths, nil
+	}
+}
+
+// newSourceHeap creates a heap tree starting with every source city as first node
+func newSourceHeap(sources []string) *heapTree {
 	heapT := newHeap()
-	heapT.push(directPath{duration: 0, nodes: []flightpath.ScheduleDetail{source}})
+	for _, source := range sources {
+		heapT.push(directPath{duration: 0, node: flightpath.ScheduleDetail{City: source}})
+	}
+	return heapT
+}
+
+// newCitySet creates a set of given cities
+func newCitySet(cities []string) map[string]bool {
+	citySet := make(map[string]bool, len(cities))
+	for _, city := range cities {
+		citySet[city] = true
+	}
+	return citySet
+}
 
-	// list of visited nodes to keep track of node
-	visitedNode := make(map[string]bool)
+// getShortestPaths gets up to k shortest paths from any of the source cities to any of the destination cities
+// paths are ranked by the cost of the objective, their total duration and then by the number of stops
+// search stops once the context is done, as the heap can grow very large for a dense network
+func (g *graph) getShortestPaths(ctx context.Context, sources, destinations []string, options searchOptions) ([]directPath, error) {
+	k := options.k
 
-	// since there can be multiple shortest path, we will decide later which path is better, hence keeping an array of shortest paths
-	shortestPaths := make(map[int64][][]flightpath.ScheduleDetail, 0)
-	shortestDuration := int64(0)
+	heapT := newSourceHeap(sources)
+	isDestination := newCitySet(destinations)
+
+	// number of times a node has been settled i.e. popped from the heap
+	// the i-th shortest path to the destination can only pass through the first i shortest paths to any node,
+	// hence a node needs to be settled at most k times
+	settledNode := make(map[string]int)
+
+	// paths are popped from the heap in ascending order, so the first k paths reaching destination are the k shortest paths
+	shortestPaths := make([]directPath, 0, k)
+
+	for len(*heapT.Values) > 0 && len(shortestPaths) < k {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// find the nearest node that is yet to be settled k times
+		p := heapT.pop()
+		node := p.node
+
+		nodeKey := options.nodeKey(p)
+		if settledNode[nodeKey] >= k {
+			continue
+		}
+		settledNode[nodeKey]++
+
+		// path has reached the destination, there is no need to fly any further
+		if isDestination[node.City] {
+			shortestPaths = append(shortestPaths, p)
+			continue
+		}
+
+		// add all the paths that can be made by taking one more flight from this node to the heap
+		g.pushNextPaths(heapT, p, isDestination, options)
+	}
+
+	return shortestPaths, nil
+}
+
+// getShortestPathsByArrival gets the shortest path to every arrival at any of the destination cities
+// i.e. one path for each distinct destination city & arrival time, paths are in their ranked order
+func (g *graph) getShortestPathsByArrival(ctx context.Context, sources, destinations []string, options searchOptions) ([]directPath, error) {
+	heapT := newSourceHeap(sources)
+	isDestination := newCitySet(destinations)
+
+	// each node is settled only once, as only the shortest path to each node is needed
+	settledNode := make(map[string]bool)
+
+	shortestPaths := make([]directPath, 0)
 
 	for len(*heapT.Values) > 0 {
-		// find the nearest node that is yet to be visitedNode
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		p := heapT.pop()
-		node := p.nodes[len(p.nodes)-1]
+		node := p.node
 
-		// if the node is visited then continue
-		if visitedNode[node.City+"_"+strconv.FormatInt(node.Timestamp, 10)] {
+		nodeKey := options.nodeKey(p)
+		if settledNode[nodeKey] {
 			continue
 		}
+		settledNode[nodeKey] = true
 
-		// if we have traversed the complete tree i.e. the last node in the heap is source node then we have found our shortest path
-		// add this shortest path to the shortest paths array
-		// update the value of shortestDuration
-		if node.City == destination.City {
-			if len(shortestPaths) != 0 && p.duration > shortestDuration {
-				continue
-			}
-			storedPaths := shortestPaths[p.duration]
-			storedPaths = append(storedPaths, p.nodes)
-			shortestPaths[p.duration] = storedPaths
-			shortestDuration = p.duration
+		if isDestination[node.City] {
+			shortestPaths = append(shortestPaths, p)
 			continue
 		}
 
-		// get all the edges of the given node from the graph
-		edges := g.getEdges(node.City)
-		originFlightTimestamp := int64(0)
-		for _, e := range edges {
-			// if any node at the end of edge is not visited yet, then add it to heap with the path duration
-			if !visitedNode[e.Schedule.City+"_"+strconv.FormatInt(e.Schedule.Timestamp, 10)] {
-				if node.City != source.City && node.Timestamp > e.OriginFlightTimestamp {
-					continue
-				}
-
-				gapBetweenFlights := int64(0)
-				// handle specific case of source schedule
-				// as there can be multiple flights from the source, and we don't know from where to start
-				// so we added source in the heap with 0 flight timestamp
-				// hence, we are updating the timestamp to its correct value before adding it to the heap
-				var updatedNodes []flightpath.ScheduleDetail
-				var updatedPNodes []flightpath.ScheduleDetail
-				if node.City == source.City && node.Timestamp == 0 {
-					for _, v := range p.nodes {
-						if v.City == source.City && v.Timestamp == 0 {
-							v.Timestamp = e.OriginFlightTimestamp
-							originFlightTimestamp = e.OriginFlightTimestamp
-						}
-						updatedPNodes = append(updatedPNodes, v)
-					}
-				} else {
-					updatedPNodes = p.nodes
-				}
-
-				// handle case when there is gap between arrival and departure in connecting cities
-				if node.Timestamp > 0 && e.OriginFlightTimestamp-node.Timestamp > 0 {
-					gapBetweenFlights = e.OriginFlightTimestamp - node.Timestamp
-					updatedPNodes = append(updatedPNodes, flightpath.ScheduleDetail{
-						City:      node.City,
-						Timestamp: e.OriginFlightTimestamp,
-					})
-				}
-
-				updatedNodes = append(updatedNodes, append(updatedPNodes, e.Schedule)...)
-				if originFlightTimestamp == 0 {
-					originFlightTimestamp = e.Schedule.Timestamp
-				}
-
-				// do not add reverse paths, this is to make sure that only directed paths are added to the heap
-				if !e.Reverse {
-					heapT.push(directPath{duration: p.duration + e.Duration + gapBetweenFlights, nodes: updatedNodes})
-				}
-				visitedNode[node.City+"_"+strconv.FormatInt(originFlightTimestamp, 10)] = true
-			}
+		g.pushNextPaths(heapT, p, isDestination, options)
+	}
+
+	return shortestPaths, nil
+}
+
+// pushNextPaths adds all the paths that can be made by taking one more flight from the path to the heap
+// paths reaching the destination are recorded as candidates when the search is traced
+func (g *graph) pushNextPaths(heapT *heapTree, p directPath, isDestination map[string]bool, options searchOptions) {
+	for _, nextPath := range g.getNextPaths(p, options) {
+		if isDestination[nextPath.node.City] {
+			options.trace.addCandidate(nextPath)
+		}
+		heapT.push(nextPath)
+	}
+}
+
+// getNextPaths gets all the paths that can be made by taking one more flight from the last node of given path
+func (g *graph) getNextPaths(p directPath, options searchOptions) []directPath {
+	node := p.node
+	options.trace.expandNode()
+
+	// get all the edges of the given node from the graph
+	edges := g.getEdges(node.City)
+	nextPaths := make([]directPath, 0, len(edges))
+	for _, e := range edges {
+		// do not follow reverse paths, this is to make sure that only directed paths are added to the heap
+		if e.Reverse {
+			continue
 		}
 
-		node.Timestamp = originFlightTimestamp
-		visitedNode[node.City+"_"+strconv.FormatInt(node.Timestamp, 10)] = true
+		if !p.canTakeFlight(e.OriginFlightTimestamp, e.Flight, options) {
+			continue
+		}
+		nextPaths = append(nextPaths, p.takeFlight(e, options))
 	}
+	return nextPaths
+}
 
-	return shortestDuration, shortestPaths
+// canTakeFlight tells if a flight departing at given time from the last node of the path can be taken as the next flight
+func (p directPath) canTakeFlight(departure int64, flight *flightpath.FlightIdentity, options searchOptions) bool {
+	// source node does not have a timestamp, as there can be multiple flights from the source
+	// for every other node the connecting flight must depart after the arrival at the node
+	// leaving at least minimum connection time and at most maximum layover in between
+	atSource := len(p.legs) == 0
+	if !atSource && departure < p.node.Timestamp {
+		options.trace.pruneConnection()
+		return false
+	}
+	if !atSource && !options.isValidConnection(p.node.City, p.node.Timestamp, departure) {
+		return false
+	}
+	if atSource && (departure < options.departAfter || (options.departBy != 0 && departure > options.departBy)) {
+		return false
+	}
+
+	// search constraints are applied while taking every flight, so that the best path satisfying them is found
+	return options.isAllowedFlight(p, p.node.City, flight)
+}
+
+// takeFlight gets the path made by taking the flight of given edge from the last node of the path
+func (p directPath) takeFlight(e edge, options searchOptions) directPath {
+	node := p.node
+
+	// handle case when there is gap between arrival and departure in connecting cities
+	// source node does not have any gap, as the path starts with the departure of first flight
+	gapBetweenFlights := int64(0)
+	if len(p.legs) != 0 {
+		gapBetweenFlights = e.OriginFlightTimestamp - node.Timestamp
+	}
+
+	// arrival node of the path carries identity of the flight used to reach there
+	arrival := e.Schedule
+	arrival.Flight = e.Flight
+
+	updatedLegs := make([]leg, 0, len(p.legs)+1)
+	updatedLegs = append(updatedLegs, p.legs...)
+	updatedLegs = append(updatedLegs, leg{
+		departure: flightpath.ScheduleDetail{
+			City:      node.City,
+			Timestamp: e.OriginFlightTimestamp,
+		},
+		arrival: arrival,
+		flight:  e.Flight,
+		fare:    e.Fare,
+	})
+
+	updatedPath := directPath{
+		duration:            p.duration + e.Duration + gapBetweenFlights,
+		fare:                p.fare + getFareInMinorUnits(e.Fare),
+		node:                arrival,
+		legs:                updatedLegs,
+		nonPreferredFlights: p.nonPreferredFlights,
+	}
+	if !options.isPreferredFlight(e.Flight) {
+		updatedPath.nonPreferredFlights++
+	}
+	updatedPath.cost = options.cost(updatedPath)
+	return updatedPath
 }
@@ -0,0 +1,108 @@
+package flightpath
+
+import (
+	"context"
+	"errors"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/errorconsts"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
+	"strconv"
+)
+
+// routingEngine is an algorithm which finds paths over the flight schedules
+// every engine finds the same paths for the same search options, so that engines can be swapped and cross checked with each other
+type routingEngine interface {
+	// supports tells if the engine can search for the best paths under given objective
+	supports(objective string) bool
+
+	// newNetwork converts the flight schedules into the network searched by the engine
+	newNetwork(schedules []*flightpath.FlightDetail) (network, error)
+}
+
+// network is the flight schedules in the form searched by a routing engine
+// network is not modified while searching, so it can be built once and searched for many trips concurrently
+type network interface {
+	// findPaths finds the paths from any of the source cities to any of the destination cities as per the options
+	// i.e. up to k shortest paths, shortest path to every arrival at the destination cities or pareto optimal paths
+	// search stops with error of the context once the context is done
+	findPaths(ctx context.Context, sources, destinations []string, options searchOptions) ([]directPath, error)
+}
+
+const (
+	// maxWalksPerPath limits the walks searched for each path asked for, so that a timetable with flights of zero duration
+	// flying back and forth between cities at the same time, which has endless walks, does not keep the search running forever
+	maxWalksPerPath = 1024
+)
+
+// errWalksLimitReached is logged when the walks searched reach their limit before k loopless paths are found among them
+var errWalksLimitReached = errors.New("limit of walks searched for loopless paths reached")
+
+// engines is the registry of routing engines by their name
+var engines = map[string]routingEngine{
+	literals.Dijkstra:   dijkstraEngine{},
+	literals.CSA:        csaEngine{},
+	literals.BruteForce: bruteForceEngine{},
+}
+
+// getEngine gets the routing engine with given name which supports the objective
+func getEngine(name, objective string) (routingEngine, error) {
+	engine, ok := engines[name]
+	if !ok || !engine.supports(objective) {
+		return nil, errors.New(errorconsts.InvalidEngine)
+	}
+	return engine, nil
+}
+
+// findLooplessPaths finds the paths of the network as per the options, paths which visit a city more than once are dropped
+// engines rank walks i.e. paths which can fly back to a city they have already visited e.g. A -> B -> A -> Z, which is never a useful itinerary
+// hence up to k shortest paths are found by searching twice as many walks until k of them do not visit any city twice or there are no more walks
+// every loopless path ranks before the walks made by adding loops to it, so the loopless paths among the first walks are the shortest paths
+// walks searched are limited to maxWalksPerPath for each path asked for, fewer than k paths are returned once the limit is reached
+// even if there are more loopless paths, which is logged as a warning
+// shortest path to an arrival and pareto optimal paths never have loops, as the path without the loop is as good in every criterion
+func findLooplessPaths(ctx context.Context, scheduleNetwork network, sources, destinations []string, options searchOptions) ([]directPath, error) {
+	if options.allArrivals || options.objective == literals.Pareto {
+		paths, err := scheduleNetwork.findPaths(ctx, sources, destinations, options)
+		if err != nil {
+			return nil, err
+		}
+		return getLooplessPaths(paths), nil
+	}
+
+	k := options.k
+	for {
+		paths, err := scheduleNetwork.findPaths(ctx, sources, destinations, options)
+		if err != nil {
+			return nil, err
+		}
+
+		looplessPaths := getLooplessPaths(paths)
+		if len(looplessPaths) >= k || len(paths) < options.k {
+			if len(looplessPaths) > k {
+				looplessPaths = looplessPaths[:k]
+			}
+			return looplessPaths, nil
+		}
+		if options.k >= k*maxWalksPerPath {
+			logger.Warn(literals.LazyJack, "searched "+strconv.Itoa(len(paths))+" walks but found only "+strconv.Itoa(len(looplessPaths))+
+				" of "+strconv.Itoa(k)+" loopless paths among them, more paths are not searched", errWalksLimitReached, nil)
+			return looplessPaths, nil
+		}
+
+		// candidates of the search are recorded again by the next search, while expanded nodes add up
+		options.k *= 2
+		options.trace.restart()
+	}
+}
+
+// getLooplessPaths gets the paths which do not visit any city more than once, paths stay in their ranked order
+func getLooplessPaths(paths []directPath) []directPath {
+	looplessPaths := make([]directPath, 0, len(paths))
+	for _, p := range paths {
+		if p.isLoopless() {
+			looplessPaths = append(looplessPaths, p)
+		}
+	}
+	return looplessPaths
+}
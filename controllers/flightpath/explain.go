@@ -0,0 +1,192 @@
+package flightpath
+
+import (
+	"context"
+	"errors"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/errorconsts"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+	"strconv"
+)
+
+const (
+	// maxExplainedCandidates is maximum number of rejected candidates returned in the explanation, best ranked candidates are returned
+	maxExplainedCandidates = 20
+)
+
+// searchTrace records what happened during a search, so that the choice of the itineraries can be explained
+// nil trace records nothing, which is the case unless explanation of the search is asked
+type searchTrace struct {
+	nodesExpanded     int
+	connectionsPruned int
+	candidates        []directPath // best ranked paths which reached the destination
+	maxCandidates     int
+}
+
+// newSearchTrace creates a trace which keeps enough candidates to explain up to k selected paths
+func newSearchTrace(k int) *searchTrace {
+	return &searchTrace{maxCandidates: k + maxExplainedCandidates}
+}
+
+// expandNode records that paths are extended from a node
+func (t *searchTrace) expandNode() {
+	if t != nil {
+		t.nodesExpanded++
+	}
+}
+
+// pruneConnection records that a connecting flight is skipped because it had already departed
+func (t *searchTrace) pruneConnection() {
+	t.pruneConnections(1)
+}
+
+// pruneConnections records that a connecting flight is skipped by given number of paths because it had already departed
+func (t *searchTrace) pruneConnections(paths int) {
+	if t != nil {
+		t.connectionsPruned += paths
+	}
+}
+
+// addCandidate records a path which reached the destination, paths which visit a city more than once are never itineraries
+func (t *searchTrace) addCandidate(p directPath) {
+	if t != nil && p.isLoopless() {
+		t.candidates = mergePaths(t.candidates, []directPath{p}, t.maxCandidates, isBetterPath)
+	}
+}
+
+// restart forgets the candidates when the search runs again, as the next search records them again
+func (t *searchTrace) restart() {
+	if t != nil {
+		t.candidates = nil
+	}
+}
+
+// ExplainItineraries finds shortest itineraries along with the explanation of the search
+// i.e. how much of the network was searched and why the other itineraries reaching end city were not chosen
+// multi city trips and round trips run many searches, so they cannot be explained
+// explanation is not cached, hence the search runs in the context of the request until maximum search time
+func (c *Controller) ExplainItineraries(ctx context.Context, data flightpath.LazyJackRequest) ([]*flightpath.Itinerary, *flightpath.Explanation, error) {
+	ctx, cancel := withSearchDeadline(ctx)
+	defer cancel()
+
+	err := validateTripPlan(data.TripPlan)
+	if err != nil {
+		return nil, nil, err
+	}
+	if data.RoundTrip != nil || len(data.TripPlan.Waypoints) != 0 {
+		return nil, nil, errors.New(errorconsts.InvalidExplain)
+	}
+
+	// route against stored flight schedules if schedules are not provided in the request
+	err = c.loadStoredSchedules(&data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	options, err := c.newSearchOptions(data, data.K)
+	if err != nil {
+		return nil, nil, err
+	}
+	trace := newSearchTrace(options.k)
+	options.trace = trace
+
+	paths, timeZones, err := c.findPaths(ctx, data, options)
+	if err != nil {
+		return nil, nil, getSearchError(err)
+	}
+
+	itineraries, err := getItineraries(paths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	explanation := &flightpath.Explanation{
+		Engine:            options.engineName,
+		NodesExpanded:     trace.nodesExpanded,
+		ConnectionsPruned: trace.connectionsPruned,
+		Candidates:        make([]*flightpath.Candidate, 0, len(trace.candidates)),
+	}
+	candidateItineraries := make([]*flightpath.Itinerary, 0, len(trace.candidates))
+	for i, candidate := range trace.candidates {
+		itinerary := candidate.itinerary()
+		explanation.Candidates = append(explanation.Candidates, &flightpath.Candidate{
+			Itinerary:      itinerary,
+			Selected:       isSelectedPath(candidate, paths),
+			RejectedReason: getRejectedReason(candidate, trace.candidates[:i], paths, options),
+		})
+		candidateItineraries = append(candidateItineraries, itinerary)
+	}
+
+	// render utc & local times of the flight plans if flight schedules are given in time or local time
+	timeZones.renderItineraries(itineraries)
+	timeZones.renderItineraries(candidateItineraries)
+	return itineraries, explanation, nil
+}
+
+// isSelectedPath tells if the path is one of the selected paths
+func isSelectedPath(p directPath, selectedPaths []directPath) bool {
+	for _, selectedPath := range selectedPaths {
+		if compareLegs(p.legs, selectedPath.legs) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// getRejectedReason tells why the path is not one of the selected paths, it is empty for a selected path
+// a pareto path is rejected because a selected path dominates it, any other path is rejected because it ranks after the path ranked
+// just above it among better ranked candidates i.e. it is worse in the first criterion of the ranking in which they differ
+func getRejectedReason(p directPath, betterCandidates, selectedPaths []directPath, options searchOptions) string {
+	if isSelectedPath(p, selectedPaths) {
+		return ""
+	}
+
+	if options.objective == literals.Pareto {
+		for i, selectedPath := range selectedPaths {
+			if isDominated(p, []directPath{selectedPath}) {
+				return "dominated by itinerary " + strconv.Itoa(i+1) + " which is as fast, as cheap and with as few stops"
+			}
+		}
+		return "not among the first k pareto optimal itineraries"
+	}
+
+	// path ranked just above is named by its number among the itineraries if it is selected, otherwise among the candidates
+	abovePath, aboveName := selectedPaths[0], "itinerary 1"
+	if i := len(betterCandidates) - 1; i >= 0 {
+		abovePath, aboveName = betterCandidates[i], "candidate "+strconv.Itoa(i+1)
+		for j, selectedPath := range selectedPaths {
+			if compareLegs(abovePath.legs, selectedPath.legs) == 0 {
+				aboveName = "itinerary " + strconv.Itoa(j+1)
+			}
+		}
+	}
+
+	reason := "tie break order of flights"
+	switch {
+	case p.cost != abovePath.cost:
+		reason = getCostReason(options.objective)
+	case p.duration != abovePath.duration:
+		reason = "longer duration"
+	case p.fare != abovePath.fare:
+		reason = "higher fare"
+	case p.flights() != abovePath.flights():
+		reason = "more stops"
+	case p.nonPreferredFlights != abovePath.nonPreferredFlights:
+		reason = "more flights of carriers which are not preferred"
+	case p.departureTimestamp() != abovePath.departureTimestamp():
+		reason = "later departure among equal itineraries"
+	}
+	return reason + " than " + aboveName
+}
+
+// getCostReason tells why a path with higher cost of the objective is worse
+func getCostReason(objective string) string {
+	switch objective {
+	case literals.EarliestArrival:
+		return "later arrival"
+	case literals.LatestDeparture:
+		return "earlier departure"
+	default:
+		return "longer duration"
+	}
+}
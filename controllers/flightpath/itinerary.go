@@ -0,0 +1,61 @@
+package flightpath
+
+import (
+	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+)
+
+// itinerary converts the path into legs along with total in air time, layover time and number of stops
+func (p directPath) itinerary() *flightpath.Itinerary {
+	itinerary := &flightpath.Itinerary{
+		Legs:          make([]*flightpath.Leg, 0, len(p.legs)),
+		TotalDuration: p.duration,
+	}
+
+	for i, l := range p.legs {
+		arrival := l.arrival
+		arrival.Flight = nil
+
+		// layover is the gap between arrival of this leg and departure of the next leg
+		layover := int64(0)
+		if i < len(p.legs)-1 {
+			layover = p.legs[i+1].departure.Timestamp - l.arrival.Timestamp
+		}
+
+		flightDuration := l.arrival.Timestamp - l.departure.Timestamp
+		itinerary.Legs = append(itinerary.Legs, &flightpath.Leg{
+			Departure:      l.departure,
+			Arrival:        arrival,
+			Flight:         l.flight,
+			Fare:           l.fare,
+			FlightDuration: flightDuration,
+			Layover:        layover,
+		})
+		itinerary.InAirTime += flightDuration
+		itinerary.LayoverTime += layover
+
+		// all the fares are in same currency, which is validated while generating graph
+		if l.fare != nil {
+			itinerary.TotalFare = getFareFromMinorUnits(p.fare, l.fare.Currency)
+		}
+	}
+
+	if len(p.legs) > 0 {
+		itinerary.Stops = len(p.legs) - 1
+	}
+	return itinerary
+}
+
+// getFlightPlan converts the itinerary into flat list of cities with timestamps, where arrival city carries the flight identity
+// a city is added twice in the list if there is a layover between arrival and departure of connecting flights
+func getFlightPlan(itinerary *flightpath.Itinerary) []flightpath.ScheduleDetail {
+	flightPlan := make([]flightpath.ScheduleDetail, 0, 2*len(itinerary.Legs)+1)
+	for i, l := range itinerary.Legs {
+		if i == 0 || itinerary.Legs[i-1].Layover > 0 {
+			flightPlan = append(flightPlan, l.Departure)
+		}
+		arrival := l.Arrival
+		arrival.Flight = l.Flight
+		flightPlan = append(flightPlan, arrival)
+	}
+	return flightPlan
+}
@@ -0,0 +1,106 @@
+package flightpath
+
+import (
+	"context"
+	"errors"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/errorconsts"
+	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+)
+
+// validateTripPlan checks that every segment of the trip ends in a different city than where it starts
+// a multi city trip can come back to a city it has visited before, e.g. round trip through waypoints
+func validateTripPlan(tripPlan *flightpath.TripDetail) error {
+	if len(tripPlan.Waypoints) == 0 {
+		if tripPlan.StartCity == tripPlan.EndCity {
+			return errors.New(errorconsts.SameStartEndCity)
+		}
+		return nil
+	}
+
+	previousCity := tripPlan.StartCity
+	for _, waypoint := range tripPlan.Waypoints {
+		if waypoint == nil || waypoint.City == "" || waypoint.City == previousCity || waypoint.MinStay < 0 {
+			return errors.New(errorconsts.InvalidWaypoint)
+		}
+		previousCity = waypoint.City
+	}
+	if previousCity == tripPlan.EndCity {
+		return errors.New(errorconsts.InvalidWaypoint)
+	}
+	return nil
+}
+
+// findMultiCityItineraries finds the itinerary of a multi city trip by chaining searches of its segments
+// each segment is searched for the best path under the objective of the request, departing only after arrival of the previous segment
+// plus minimum stay at the waypoint, which is at least minimum connection time of the waypoint
+func (c *Controller) findMultiCityItineraries(ctx context.Context, data flightpath.LazyJackRequest) ([]*flightpath.Itinerary, error) {
+	options, err := c.newSearchOptions(data, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// network is built once for the whole trip, each segment searches it from the arrival of the previous segment
+	scheduleNetwork, timeZones, err := c.buildNetwork(data, options)
+	if err != nil {
+		return nil, err
+	}
+
+	tripPlan := data.TripPlan
+	startCity := tripPlan.StartCity
+	segmentOptions := options
+	segments := make([]directPath, 0, len(tripPlan.Waypoints)+1)
+	for i := 0; i <= len(tripPlan.Waypoints); i++ {
+		endCity := tripPlan.EndCity
+		if i < len(tripPlan.Waypoints) {
+			endCity = tripPlan.Waypoints[i].City
+		}
+
+		sources, destinations, err := c.getTripCities(&flightpath.TripDetail{
+			StartCity: startCity,
+			EndCity:   endCity,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		paths, err := findLooplessPaths(ctx, scheduleNetwork, sources, destinations, segmentOptions)
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return nil, errors.New(errorconsts.NoFlightsAvailable)
+		}
+		segment := paths[0]
+		segments = append(segments, segment)
+
+		// next segment departs from the waypoint, which can be any of its airports if it is a metro area
+		if i < len(tripPlan.Waypoints) {
+			startCity = endCity
+			segmentOptions.departAfter = segment.node.Timestamp + options.getMinStay(segment.node.City, tripPlan.Waypoints[i].MinStay)
+		}
+	}
+
+	itinerary := joinPaths(segments).itinerary()
+	itinerary.Segments = make([]*flightpath.Itinerary, 0, len(segments))
+	for _, segment := range segments {
+		itinerary.Segments = append(itinerary.Segments, segment.itinerary())
+	}
+
+	itineraries := []*flightpath.Itinerary{itinerary}
+	timeZones.renderItineraries(itineraries)
+	return itineraries, nil
+}
+
+// joinPaths joins the paths of consecutive segments into a single path of the whole trip
+// duration of the trip includes stays at the waypoints
+func joinPaths(segments []directPath) directPath {
+	trip := directPath{
+		node: segments[len(segments)-1].node,
+	}
+	for _, segment := range segments {
+		trip.legs = append(trip.legs, segment.legs...)
+		trip.fare += segment.fare
+	}
+	trip.duration = trip.node.Timestamp - trip.departureTimestamp()
+	return trip
+}
@@ -0,0 +1,223 @@
+package flightpath
+
+import (
+	"errors"
+	"github.com/somprabhsharma/the-lazy-traveler/constants"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/errorconsts"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+	"strconv"
+	"strings"
+)
+
+// searchOptions are the options which decide how the paths are searched and ranked
+type searchOptions struct {
+	k         int // maximum number of paths to return
+	objective string
+
+	engine     routingEngine
+	engineName string
+	// trace records the search when its explanation is asked, it is nil otherwise
+	trace *searchTrace
+
+	// connection constraints applied at every connecting city, cityConnectionRules override them per city
+	minConnectionTime   int64
+	maxLayover          int64
+	cityConnectionRules map[string]*flightpath.ConnectionRule
+
+	// departAfter is the earliest departure of the first flight of a path, so that a network built once can be searched from different times
+	// departBy is the latest departure of the first flight of a path, zero means there is no limit for both of them
+	departAfter int64
+	departBy    int64
+	// allArrivals finds the shortest path to every arrival at the destination instead of k shortest paths
+	allArrivals bool
+
+	// search constraints, zero maxFlights means there is no limit on number of flights
+	maxFlights        int
+	excludedCities    map[string]bool
+	excludedCarriers  map[string]bool
+	preferredCarriers map[string]bool
+}
+
+// newSearchOptions creates search options from the request data
+// a metro area excluded as connection point excludes all its airports, and connection rule of a metro area applies to all its airports
+// unless an airport has its own rule
+func (c *Controller) newSearchOptions(data flightpath.LazyJackRequest, k int) (searchOptions, error) {
+	objective := data.Objective
+	if objective == "" {
+		objective = literals.MinDuration
+	}
+
+	if objective != literals.MinDuration && objective != literals.EarliestArrival && objective != literals.LatestDeparture && objective != literals.Pareto {
+		return searchOptions{}, errors.New(errorconsts.InvalidObjective)
+	}
+
+	// default engine of the server is used if request does not ask for an engine
+	engineName := data.Engine
+	if engineName == "" {
+		engineName = constants.Env.DefaultEngine
+	}
+	engine, err := getEngine(engineName, objective)
+	if err != nil {
+		return searchOptions{}, err
+	}
+
+	// all the pareto optimal paths are returned unless limited by k, every other objective returns 1 path by default
+	if k <= 0 && objective != literals.Pareto {
+		k = 1
+	}
+
+	options := searchOptions{
+		k:                   k,
+		objective:           objective,
+		engine:              engine,
+		engineName:          engineName,
+		minConnectionTime:   data.MinConnectionTime,
+		maxLayover:          data.MaxLayover,
+		cityConnectionRules: make(map[string]*flightpath.ConnectionRule, len(data.ConnectionRules)),
+		excludedCities:      make(map[string]bool),
+		excludedCarriers:    newCarrierSet(data.ExcludedCarriers),
+		preferredCarriers:   newCarrierSet(data.PreferredCarriers),
+	}
+
+	// validate search constraints
+	switch {
+	case data.DirectOnly:
+		options.maxFlights = 1
+	case data.MaxStops != nil:
+		if *data.MaxStops < 0 {
+			return searchOptions{}, errors.New(errorconsts.InvalidSearchConstraint)
+		}
+		options.maxFlights = *data.MaxStops + 1
+	}
+	for carrier := range options.preferredCarriers {
+		if options.excludedCarriers[carrier] {
+			return searchOptions{}, errors.New(errorconsts.InvalidSearchConstraint)
+		}
+	}
+	for _, city := range data.ExcludedCities {
+		for _, airport := range c.Dao.CityCatalog.Airports(city) {
+			options.excludedCities[airport] = true
+		}
+	}
+	for city, rule := range data.ConnectionRules {
+		for _, airport := range c.Dao.CityCatalog.Airports(city) {
+			if _, ok := data.ConnectionRules[airport]; !ok || airport == city {
+				options.cityConnectionRules[airport] = rule
+			}
+		}
+	}
+
+	// validate connection constraints of every connecting city
+	if !isValidConnectionRule(options.minConnectionTime, options.maxLayover) {
+		return searchOptions{}, errors.New(errorconsts.InvalidConnectionRule)
+	}
+	for city, rule := range options.cityConnectionRules {
+		if rule == nil || rule.MinConnectionTime < 0 || rule.MaxLayover < 0 {
+			return searchOptions{}, errors.New(errorconsts.InvalidConnectionRule)
+		}
+		if !isValidConnectionRule(options.getMinConnectionTime(city), options.getMaxLayover(city)) {
+			return searchOptions{}, errors.New(errorconsts.InvalidConnectionRule)
+		}
+	}
+
+	return options, nil
+}
+
+// newCarrierSet creates a set of given carriers, carriers are matched case insensitively
+func newCarrierSet(carriers []string) map[string]bool {
+	carrierSet := make(map[string]bool, len(carriers))
+	for _, carrier := range carriers {
+		carrierSet[strings.ToUpper(strings.TrimSpace(carrier))] = true
+	}
+	return carrierSet
+}
+
+// isValidConnectionRule tells if given minimum connection time and maximum layover can be satisfied, zero maxLayover means no limit
+func isValidConnectionRule(minConnectionTime, maxLayover int64) bool {
+	if minConnectionTime < 0 || maxLayover < 0 {
+		return false
+	}
+	return maxLayover == 0 || minConnectionTime <= maxLayover
+}
+
+// getMinConnectionTime gets minimum time required to connect to another flight in given city
+func (o searchOptions) getMinConnectionTime(city string) int64 {
+	if rule, ok := o.cityConnectionRules[city]; ok && rule != nil && rule.MinConnectionTime != 0 {
+		return rule.MinConnectionTime
+	}
+	return o.minConnectionTime
+}
+
+// getMaxLayover gets maximum time allowed between arrival and connecting departure in given city, zero means no limit
+func (o searchOptions) getMaxLayover(city string) int64 {
+	if rule, ok := o.cityConnectionRules[city]; ok && rule != nil && rule.MaxLayover != 0 {
+		return rule.MaxLayover
+	}
+	return o.maxLayover
+}
+
+// getMinStay gets minimum time to stay in given city before departing on the next trip, which is at least minimum connection time of the city
+func (o searchOptions) getMinStay(city string, minStay int64) int64 {
+	if minConnectionTime := o.getMinConnectionTime(city); minConnectionTime > minStay {
+		return minConnectionTime
+	}
+	return minStay
+}
+
+// isValidConnection tells if a flight departing at departure can be connected with an arrival in given city at arrival
+func (o searchOptions) isValidConnection(city string, arrival, departure int64) bool {
+	gap := departure - arrival
+	if gap < o.getMinConnectionTime(city) {
+		return false
+	}
+	maxLayover := o.getMaxLayover(city)
+	return maxLayover == 0 || gap <= maxLayover
+}
+
+// cost returns the cost of a path as per the objective, path with less cost is preferred
+// paths with same cost are further ranked by duration, number of stops and departure time
+func (o searchOptions) cost(p directPath) int64 {
+	switch o.objective {
+	case literals.EarliestArrival:
+		// arrival time at the last node of the path
+		return p.node.Timestamp
+	case literals.LatestDeparture:
+		// negative of departure time from the source, so that later departure has less cost
+		return -p.departureTimestamp()
+	default:
+		// pareto paths are also popped in ascending order of duration
+		return p.duration
+	}
+}
+
+// nodeKey gets key of the last node of the path, which identifies the node while settling paths
+// when number of flights is limited, paths with different number of flights settle separately at a node
+// so that a faster path with more flights does not prune a slower path which can still take more flights
+func (o searchOptions) nodeKey(p directPath) string {
+	nodeKey := p.node.City + "_" + strconv.FormatInt(p.node.Timestamp, 10)
+	if o.maxFlights != 0 {
+		nodeKey = nodeKey + "_" + strconv.Itoa(p.flights())
+	}
+	return nodeKey
+}
+
+// isAllowedFlight tells if a flight departing from given city can be taken as the next flight of the path
+// flights cannot depart from excluded cities except the source, exceed maximum number of flights or be operated by excluded carriers
+func (o searchOptions) isAllowedFlight(p directPath, city string, flight *flightpath.FlightIdentity) bool {
+	if p.flights() > 0 && o.excludedCities[city] {
+		return false
+	}
+	if o.maxFlights != 0 && p.flights() >= o.maxFlights {
+		return false
+	}
+	return flight == nil || !o.excludedCarriers[strings.ToUpper(flight.Carrier)]
+}
+
+// isPreferredFlight tells if the flight is operated by one of the preferred carriers, every flight is preferred if there is no preferred carrier
+func (o searchOptions) isPreferredFlight(flight *flightpath.FlightIdentity) bool {
+	if len(o.preferredCarriers) == 0 {
+		return true
+	}
+	return flight != nil && o.preferredCarriers[strings.ToUpper(flight.Carrier)]
+}
@@ -0,0 +1,88 @@
+package flightpath
+
+import (
+	"context"
+	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+	"math"
+)
+
+const (
+	// fares are added in minor units to avoid floating point errors, 1000 minor units cover every currency
+	fareMinorUnits = 1000
+	// destinationNodeKey is node key shared by all the destination cities, other node keys always have a timestamp
+	destinationNodeKey = "destination"
+)
+
+// getParetoPaths gets pareto optimal paths from any of the source cities to any of the destination cities across duration, fare and number of stops
+// i.e. paths for which there is no other path that is at least as good in all three and better in one of them
+// paths are ranked by duration, fare and then by number of stops, zero k means there is no limit on number of paths
+func (g *graph) getParetoPaths(ctx context.Context, sources, destinations []string, options searchOptions) ([]directPath, error) {
+	heapT := newSourceHeap(sources)
+	isDestination := newCitySet(destinations)
+
+	// pareto optimal paths settled at each node, a path reaching a node is dropped if it is dominated by any of them
+	// since future flights from a node cost same to every path, a dominated path can never become pareto optimal
+	settledPaths := make(map[string][]directPath)
+
+	paretoPaths := make([]directPath, 0)
+
+	for len(*heapT.Values) > 0 && (options.k == 0 || len(paretoPaths) < options.k) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		p := heapT.pop()
+		node := p.node
+
+		// all the arrivals at destination cities are compared with each other irrespective of their city and arrival time
+		nodeKey := options.nodeKey(p)
+		if isDestination[node.City] {
+			nodeKey = destinationNodeKey
+		}
+
+		// paths are popped in ascending order of duration, fare and stops
+		// hence a path can only be dominated by already settled paths and not by the paths popped later
+		if isDominated(p, settledPaths[nodeKey]) {
+			continue
+		}
+		settledPaths[nodeKey] = append(settledPaths[nodeKey], p)
+
+		// path has reached the destination, there is no need to fly any further
+		if isDestination[node.City] {
+			paretoPaths = append(paretoPaths, p)
+			continue
+		}
+
+		// add all the paths that can be made by taking one more flight from this node to the heap
+		g.pushNextPaths(heapT, p, isDestination, options)
+	}
+
+	return paretoPaths, nil
+}
+
+// isDominated tells if path is dominated by any of the given paths
+// i.e. if any path has less or equal duration, fare and number of stops
+func isDominated(p directPath, paths []directPath) bool {
+	for _, other := range paths {
+		if other.duration <= p.duration && other.fare <= p.fare && other.flights() <= p.flights() {
+			return true
+		}
+	}
+	return false
+}
+
+// getFareInMinorUnits converts fare amount into minor units, missing fare is treated as free
+func getFareInMinorUnits(fare *flightpath.Fare) int64 {
+	if fare == nil {
+		return 0
+	}
+	return int64(math.Round(fare.Amount * fareMinorUnits))
+}
+
+// getFareFromMinorUnits converts amount in minor units into fare of given currency
+func getFareFromMinorUnits(amount int64, currency string) *flightpath.Fare {
+	return &flightpath.Fare{
+		Amount:   float64(amount) / fareMinorUnits,
+		Currency: currency,
+	}
+}
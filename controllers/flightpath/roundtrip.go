@@ -0,0 +1,142 @@
+package flightpath
+
+import (
+	"context"
+	"errors"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/errorconsts"
+	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+)
+
+// validateRoundTrip checks that round trip has valid windows and stay, round trip to a multi city trip is not supported
+func validateRoundTrip(data flightpath.LazyJackRequest) error {
+	roundTrip := data.RoundTrip
+	if len(data.TripPlan.Waypoints) != 0 || !isValidTimeWindow(roundTrip.OutboundWindow) || !isValidTimeWindow(roundTrip.ReturnWindow) {
+		return errors.New(errorconsts.InvalidRoundTrip)
+	}
+	if roundTrip.MinStay < 0 || roundTrip.MaxStay < 0 || (roundTrip.MaxStay != 0 && roundTrip.MaxStay < roundTrip.MinStay) {
+		return errors.New(errorconsts.InvalidRoundTrip)
+	}
+	return nil
+}
+
+// isValidTimeWindow tells if the time window is valid, nil window means there is no limit
+func isValidTimeWindow(window *flightpath.TimeWindow) bool {
+	if window == nil {
+		return true
+	}
+	if window.From < 0 || window.To < 0 {
+		return false
+	}
+	return window.To == 0 || window.From <= window.To
+}
+
+// findRoundTripItineraries finds the best pair of outbound & return itineraries of a round trip under the objective of the request
+// the best outbound itinerary to every arrival at end city is paired with the best return itinerary departing within the stay
+// and the return window, as the return itinerary depends only on the arrival time of the outbound itinerary
+func (c *Controller) findRoundTripItineraries(ctx context.Context, data flightpath.LazyJackRequest) ([]*flightpath.Itinerary, error) {
+	err := validateRoundTrip(data)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := c.newSearchOptions(data, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	roundTrip := data.RoundTrip
+	outboundWindow := getTimeWindow(roundTrip.OutboundWindow)
+	returnWindow := getTimeWindow(roundTrip.ReturnWindow)
+
+	outboundData := data
+	outboundData.PreferredTime = maxTimestamp(data.PreferredTime, outboundWindow.From)
+	outboundOptions := options
+	outboundOptions.departBy = outboundWindow.To
+	outboundOptions.allArrivals = true
+	outbounds, timeZones, err := c.findPaths(ctx, outboundData, outboundOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	// return network is built once for the earliest possible return, each outbound searches it from its own earliest return
+	returnData := data
+	returnData.PreferredTime = maxTimestamp(data.PreferredTime, returnWindow.From)
+	returnData.TripPlan = &flightpath.TripDetail{
+		StartCity: data.TripPlan.EndCity,
+		EndCity:   data.TripPlan.StartCity,
+	}
+	returnNetwork, _, err := c.buildNetwork(returnData, options)
+	if err != nil {
+		return nil, err
+	}
+	returnSources, returnDestinations, err := c.getTripCities(returnData.TripPlan)
+	if err != nil {
+		return nil, err
+	}
+
+	var bestPair []directPath
+	var bestPath directPath
+	for _, outbound := range outbounds {
+		// return departs after the stay, which is at least minimum connection time of the city where outbound arrives
+		arrival := outbound.node
+		returnOptions := options
+		returnOptions.departAfter = maxTimestamp(arrival.Timestamp+options.getMinStay(arrival.City, roundTrip.MinStay), returnWindow.From)
+		returnOptions.departBy = returnWindow.To
+		if roundTrip.MaxStay != 0 && (returnOptions.departBy == 0 || arrival.Timestamp+roundTrip.MaxStay < returnOptions.departBy) {
+			returnOptions.departBy = arrival.Timestamp + roundTrip.MaxStay
+		}
+		if returnOptions.departBy != 0 && returnOptions.departAfter > returnOptions.departBy {
+			continue
+		}
+
+		returns, err := findLooplessPaths(ctx, returnNetwork, returnSources, returnDestinations, returnOptions)
+		if err != nil {
+			return nil, err
+		}
+		if len(returns) == 0 {
+			continue
+		}
+
+		// pair is ranked as a single path with total duration of both the itineraries, excluding the stay
+		pairPath := directPath{
+			duration: outbound.duration + returns[0].duration,
+			fare:     outbound.fare + returns[0].fare,
+			node:     returns[0].node,
+			legs:     append(append([]leg{}, outbound.legs...), returns[0].legs...),
+
+			nonPreferredFlights: outbound.nonPreferredFlights + returns[0].nonPreferredFlights,
+		}
+		pairPath.cost = options.cost(pairPath)
+		if bestPair == nil || isBetterPath(pairPath, bestPath) {
+			bestPair = []directPath{outbound, returns[0]}
+			bestPath = pairPath
+		}
+	}
+
+	if bestPair == nil {
+		return nil, errors.New(errorconsts.NoFlightsAvailable)
+	}
+
+	itinerary := joinPaths(bestPair).itinerary()
+	itinerary.Segments = []*flightpath.Itinerary{bestPair[0].itinerary(), bestPair[1].itinerary()}
+
+	itineraries := []*flightpath.Itinerary{itinerary}
+	timeZones.renderItineraries(itineraries)
+	return itineraries, nil
+}
+
+// getTimeWindow gets the time window, nil window is a window without any bound
+func getTimeWindow(window *flightpath.TimeWindow) flightpath.TimeWindow {
+	if window == nil {
+		return flightpath.TimeWindow{}
+	}
+	return *window
+}
+
+// maxTimestamp gets the later of two timestamps
+func maxTimestamp(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
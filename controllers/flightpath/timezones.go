@@ -0,0 +1,160 @@
+package flightpath
+
+import (
+	"errors"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/errorconsts"
+	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/timezone"
+	"time"
+)
+
+// cityTimeZones are time zones of the cities used to render local times in the flight plans
+// nil cityTimeZones means flight schedules are plain timestamps and no time is rendered
+type cityTimeZones map[string]*time.Location
+
+// normalizeSchedules validates the flight schedules and converts their time & local time into unix timestamps
+// every departure & arrival must have exactly one of timestamp, time & local time and no flight can arrive before it departs
+// IANA time zones of the cities are needed to convert local times, offset of the time is used for cities without IANA time zone
+// schedules having time are copied before conversion, so that given schedules are not modified
+func normalizeSchedules(schedules []*flightpath.FlightDetail, timeZoneNames map[string]string) ([]*flightpath.FlightDetail, cityTimeZones, error) {
+	locations := make(map[string]*time.Location, len(timeZoneNames))
+	for city, name := range timeZoneNames {
+		// empty name is loaded as UTC, but it is most likely a mistake
+		if name == "" {
+			return nil, nil, errors.New(errorconsts.InvalidTimeZone)
+		}
+		location, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, nil, errors.New(errorconsts.InvalidTimeZone)
+		}
+		locations[city] = location
+	}
+
+	timeZones := make(cityTimeZones, len(locations))
+	for city, location := range locations {
+		timeZones[city] = location
+	}
+
+	hasTime := len(locations) != 0
+	normalizedSchedules := make([]*flightpath.FlightDetail, 0, len(schedules))
+	for _, schedule := range schedules {
+		if schedule == nil || schedule.Departure == nil || schedule.Arrival == nil {
+			return nil, nil, errors.New(errorconsts.InvalidFlightSchedule)
+		}
+		err := validateScheduleDetail(schedule.Departure)
+		if err != nil {
+			return nil, nil, err
+		}
+		err = validateScheduleDetail(schedule.Arrival)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if hasScheduleTime(schedule.Departure) || hasScheduleTime(schedule.Arrival) {
+			hasTime = true
+
+			departure, err := normalizeScheduleDetail(*schedule.Departure, locations, timeZones)
+			if err != nil {
+				return nil, nil, err
+			}
+			arrival, err := normalizeScheduleDetail(*schedule.Arrival, locations, timeZones)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			normalizedSchedule := *schedule
+			normalizedSchedule.Departure = &departure
+			normalizedSchedule.Arrival = &arrival
+			schedule = &normalizedSchedule
+		}
+
+		// a flight cannot arrive before it departs
+		if schedule.Arrival.Timestamp < schedule.Departure.Timestamp {
+			return nil, nil, errors.New(errorconsts.InvalidFlightSchedule)
+		}
+		normalizedSchedules = append(normalizedSchedules, schedule)
+	}
+
+	if !hasTime {
+		return schedules, nil, nil
+	}
+	return normalizedSchedules, timeZones, nil
+}
+
+// validateScheduleDetail checks that schedule detail has exactly one of timestamp, time & local time
+// schedule detail without any of them would be routed at unix epoch, while schedule detail with more than one of them is ambiguous
+func validateScheduleDetail(detail *flightpath.ScheduleDetail) error {
+	count := 0
+	if detail.Timestamp != 0 {
+		count++
+	}
+	if detail.Time != "" {
+		count++
+	}
+	if detail.LocalTime != "" {
+		count++
+	}
+	if count != 1 || detail.Timestamp < 0 {
+		return errors.New(errorconsts.InvalidFlightSchedule)
+	}
+	return nil
+}
+
+// hasScheduleTime tells if schedule detail has time or local time instead of timestamp
+func hasScheduleTime(detail *flightpath.ScheduleDetail) bool {
+	return detail.Time != "" || detail.LocalTime != ""
+}
+
+// normalizeScheduleDetail sets timestamp of the schedule detail from its time or local time
+// offset of the time becomes time zone of the city if the city does not have an IANA time zone
+func normalizeScheduleDetail(detail flightpath.ScheduleDetail, locations map[string]*time.Location, timeZones cityTimeZones) (flightpath.ScheduleDetail, error) {
+	switch {
+	case detail.Time != "":
+		t, err := timezone.ParseTime(detail.Time)
+		if err != nil {
+			return detail, errors.New(errorconsts.InvalidTime)
+		}
+		detail.Timestamp = t.Unix()
+		if _, ok := timeZones[detail.City]; !ok {
+			_, offset := t.Zone()
+			timeZones[detail.City] = time.FixedZone("", offset)
+		}
+	case detail.LocalTime != "":
+		location, ok := locations[detail.City]
+		if !ok {
+			return detail, errors.New(errorconsts.InvalidTimeZone)
+		}
+		t, err := timezone.ParseLocalTime(detail.LocalTime, location)
+		if err != nil {
+			return detail, errors.New(errorconsts.InvalidTime)
+		}
+		detail.Timestamp = t.Unix()
+	}
+	return detail, nil
+}
+
+// renderItineraries sets utc time and local time of every departure & arrival of the itineraries and their segments
+func (z cityTimeZones) renderItineraries(itineraries []*flightpath.Itinerary) {
+	if z == nil {
+		return
+	}
+
+	for _, itinerary := range itineraries {
+		for _, l := range itinerary.Legs {
+			z.renderScheduleDetail(&l.Departure)
+			z.renderScheduleDetail(&l.Arrival)
+		}
+		z.renderItineraries(itinerary.Segments)
+	}
+}
+
+// renderScheduleDetail sets utc time of the schedule detail and its local time if time zone of the city is known
+func (z cityTimeZones) renderScheduleDetail(detail *flightpath.ScheduleDetail) {
+	t := time.Unix(detail.Timestamp, 0)
+	detail.Time = ""
+	detail.UTCTime = timezone.FormatTime(t, time.UTC)
+	detail.LocalTime = ""
+	if location, ok := z[detail.City]; ok {
+		detail.LocalTime = timezone.FormatTime(t, location)
+	}
+}
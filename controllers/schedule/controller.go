@@ -0,0 +1,149 @@
+package schedule
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/errorconsts"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+	"github.com/somprabhsharma/the-lazy-traveler/models"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/timezone"
+	"time"
+)
+
+// Controller is a struct which will act like a controller for stored flight schedules
+type Controller struct {
+	Dao *models.Dao
+}
+
+// NewController is a constructor for Controller struct
+func NewController(dao *models.Dao) *Controller {
+	return &Controller{
+		Dao: dao,
+	}
+}
+
+// CreateSchedule validates the flight schedule and saves it in the store with a newly generated id
+func (c *Controller) CreateSchedule(schedule flightpath.FlightDetail) (*flightpath.FlightDetail, error) {
+	err := validateSchedule(schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.ID, err = generateScheduleID()
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.Dao.ScheduleModel.Put(&schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info(literals.ScheduleStore, "successfully created flight schedule", schedule)
+	return &schedule, nil
+}
+
+// ListSchedules returns all the stored flight schedules
+func (c *Controller) ListSchedules() ([]*flightpath.FlightDetail, error) {
+	return c.Dao.ScheduleModel.List()
+}
+
+// UpdateSchedule validates the flight schedule and replaces the stored flight schedule with given id
+func (c *Controller) UpdateSchedule(id string, schedule flightpath.FlightDetail) (*flightpath.FlightDetail, error) {
+	err := validateSchedule(schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	// schedule is replaced only if it is still stored, so that an update racing a delete does not create it again
+	schedule.ID = id
+	updated, err := c.Dao.ScheduleModel.PutIfPresent(&schedule)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, errors.New(errorconsts.ScheduleNotFound)
+	}
+
+	logger.Info(literals.ScheduleStore, "successfully updated flight schedule", schedule)
+	return &schedule, nil
+}
+
+// DeleteSchedule deletes the stored flight schedule with given id
+func (c *Controller) DeleteSchedule(id string) error {
+	deleted, err := c.Dao.ScheduleModel.Delete(id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New(errorconsts.ScheduleNotFound)
+	}
+
+	logger.Info(literals.ScheduleStore, "successfully deleted flight schedule with id: "+id, nil)
+	return nil
+}
+
+// validateSchedule checks that flight schedule has valid arrival and departure details
+// departure & arrival can have time or local time instead of timestamp, local time is converted using time zones given while finding flight path
+func validateSchedule(schedule flightpath.FlightDetail) error {
+	if schedule.Arrival == nil || schedule.Departure == nil {
+		return errors.New(errorconsts.InvalidFlightSchedule)
+	}
+
+	departureTimestamp, err := getTimestamp(schedule.Departure)
+	if err != nil {
+		return err
+	}
+	arrivalTimestamp, err := getTimestamp(schedule.Arrival)
+	if err != nil {
+		return err
+	}
+
+	// order of departure & arrival can only be checked if time zone of both is known
+	if departureTimestamp != 0 && arrivalTimestamp != 0 && arrivalTimestamp < departureTimestamp {
+		return errors.New(errorconsts.InvalidFlightSchedule)
+	}
+	return nil
+}
+
+// getTimestamp gets timestamp of the schedule detail from its timestamp or time, returns 0 if it only has local time
+func getTimestamp(detail *flightpath.ScheduleDetail) (int64, error) {
+	// exactly one of timestamp, time & local time must be given, same as while finding flight path
+	hasTime := detail.Time != "" || detail.LocalTime != ""
+	if (detail.Timestamp != 0) == hasTime || (detail.Time != "" && detail.LocalTime != "") {
+		return 0, errors.New(errorconsts.InvalidFlightSchedule)
+	}
+
+	switch {
+	case detail.Time != "":
+		t, err := timezone.ParseTime(detail.Time)
+		if err != nil {
+			return 0, errors.New(errorconsts.InvalidTime)
+		}
+		return t.Unix(), nil
+	case detail.LocalTime != "":
+		// UTC does not have daylight saving time, so parsing in UTC only validates the format
+		_, err := timezone.ParseLocalTime(detail.LocalTime, time.UTC)
+		if err != nil {
+			return 0, errors.New(errorconsts.InvalidTime)
+		}
+		return 0, nil
+	case detail.Timestamp <= 0:
+		return 0, errors.New(errorconsts.InvalidFlightSchedule)
+	}
+	return detail.Timestamp, nil
+}
+
+// generateScheduleID generates a random id for a flight schedule
+func generateScheduleID() (string, error) {
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	if err != nil {
+		logger.Err(literals.ScheduleStore, "error while generating flight schedule id", err, nil)
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
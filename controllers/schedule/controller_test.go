@@ -0,0 +1,153 @@
+package schedule
+
+import (
+	"context"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/errorconsts"
+	flightpathcontroller "github.com/somprabhsharma/the-lazy-traveler/controllers/flightpath"
+	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+	"github.com/somprabhsharma/the-lazy-traveler/models"
+	"github.com/somprabhsharma/the-lazy-traveler/models/cache"
+	"testing"
+)
+
+func TestScheduleController(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "The Lazy Traveler Suite")
+}
+
+var _ = Describe("controllers", func() {
+	Context("##schedule", func() {
+		dao := models.NewDaoWithCache(cache.NewMemory(0))
+		controller := NewController(dao)
+		schedule := flightpath.FlightDetail{
+			Departure: &flightpath.ScheduleDetail{
+				City:      "STORED-A",
+				Timestamp: 1,
+			},
+			Arrival: &flightpath.ScheduleDetail{
+				City:      "STORED-Z",
+				Timestamp: 10,
+			},
+		}
+
+		It("should create flight schedule with a new id", func() {
+			createdSchedule, err := controller.CreateSchedule(schedule)
+			Expect(err).Should(BeNil())
+			Expect(createdSchedule.ID).ShouldNot(BeEmpty())
+
+			schedules, err := controller.ListSchedules()
+			Expect(err).Should(BeNil())
+			Expect(schedules).Should(ContainElement(createdSchedule))
+			_ = controller.DeleteSchedule(createdSchedule.ID)
+		})
+
+		It("should update stored flight schedule", func() {
+			createdSchedule, _ := controller.CreateSchedule(schedule)
+			updatedSchedule := schedule
+			updatedSchedule.Arrival = &flightpath.ScheduleDetail{
+				City:      "STORED-Z",
+				Timestamp: 5,
+			}
+			val, err := controller.UpdateSchedule(createdSchedule.ID, updatedSchedule)
+			Expect(err).Should(BeNil())
+			Expect(val.ID).To(Equal(createdSchedule.ID))
+			Expect(val.Arrival.Timestamp).To(Equal(int64(5)))
+			_ = controller.DeleteSchedule(createdSchedule.ID)
+		})
+
+		It("should throw error if flight schedule to be updated is not stored", func() {
+			val, err := controller.UpdateSchedule("unknown-id", schedule)
+			Expect(val).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.ScheduleNotFound))
+		})
+
+		It("should not store flight schedule again if it is updated after it is deleted", func() {
+			createdSchedule, _ := controller.CreateSchedule(schedule)
+			_ = controller.DeleteSchedule(createdSchedule.ID)
+			val, err := controller.UpdateSchedule(createdSchedule.ID, schedule)
+			Expect(val).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.ScheduleNotFound))
+
+			schedules, _ := controller.ListSchedules()
+			for _, s := range schedules {
+				Expect(s.ID).ShouldNot(Equal(createdSchedule.ID))
+			}
+		})
+
+		It("should throw error if flight schedule to be deleted is not stored", func() {
+			err := controller.DeleteSchedule("unknown-id")
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.ScheduleNotFound))
+		})
+
+		It("should throw error if flight schedule arrives before its departure", func() {
+			invalidSchedule := schedule
+			invalidSchedule.Arrival = &flightpath.ScheduleDetail{
+				City:      "STORED-Z",
+				Timestamp: 0,
+			}
+			val, err := controller.CreateSchedule(invalidSchedule)
+			Expect(val).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.InvalidFlightSchedule))
+		})
+
+		It("should throw error if departure of flight schedule has both timestamp and time", func() {
+			invalidSchedule := schedule
+			invalidSchedule.Departure = &flightpath.ScheduleDetail{
+				City:      "STORED-A",
+				Timestamp: 1,
+				Time:      "2020-01-01T10:00:00Z",
+			}
+			val, err := controller.CreateSchedule(invalidSchedule)
+			Expect(val).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.InvalidFlightSchedule))
+		})
+
+		It("should find shortest flight path using stored flight schedules", func() {
+			createdSchedule, _ := controller.CreateSchedule(schedule)
+			flightPathController := flightpathcontroller.NewController(dao)
+			shortestPath, err := flightPathController.FindShortestFlightPath(context.Background(), flightpath.LazyJackRequest{
+				TripPlan: &flightpath.TripDetail{
+					StartCity: "STORED-A",
+					EndCity:   "STORED-Z",
+				},
+			})
+			Expect(err).Should(BeNil())
+			Expect(len(shortestPath)).To(Equal(2))
+			Expect(shortestPath[0].Timestamp).To(Equal(int64(1)))
+			Expect(shortestPath[1].Timestamp).To(Equal(int64(10)))
+			_ = controller.DeleteSchedule(createdSchedule.ID)
+		})
+
+		It("should find shortest flight path using stored flight schedules once they are updated", func() {
+			createdSchedule, _ := controller.CreateSchedule(schedule)
+			flightPathController := flightpathcontroller.NewController(dao)
+			request := flightpath.LazyJackRequest{
+				TripPlan: &flightpath.TripDetail{
+					StartCity: "STORED-A",
+					EndCity:   "STORED-Z",
+				},
+			}
+			shortestPath, _ := flightPathController.FindShortestFlightPath(context.Background(), request)
+			Expect(shortestPath[1].Timestamp).To(Equal(int64(10)))
+
+			updatedSchedule := schedule
+			updatedSchedule.Arrival = &flightpath.ScheduleDetail{
+				City:      "STORED-Z",
+				Timestamp: 5,
+			}
+			_, _ = controller.UpdateSchedule(createdSchedule.ID, updatedSchedule)
+			shortestPath, err := flightPathController.FindShortestFlightPath(context.Background(), request)
+			Expect(err).Should(BeNil())
+			Expect(len(shortestPath)).To(Equal(2))
+			Expect(shortestPath[1].Timestamp).To(Equal(int64(5)))
+			_ = controller.DeleteSchedule(createdSchedule.ID)
+		})
+	})
+})
@@ -0,0 +1,99 @@
+package status
+
+import (
+	"errors"
+	"github.com/somprabhsharma/the-lazy-traveler/constants"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"github.com/somprabhsharma/the-lazy-traveler/entities/status"
+	"github.com/somprabhsharma/the-lazy-traveler/models"
+	"github.com/somprabhsharma/the-lazy-traveler/models/cache"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Controller is a struct which will act like a controller for status of the service
+type Controller struct {
+	Dao          *models.Dao
+	shuttingDown int32 // set once the server starts shutting down
+}
+
+// NewController is a constructor for Controller struct
+func NewController(dao *models.Dao) *Controller {
+	return &Controller{
+		Dao: dao,
+	}
+}
+
+// ShutDown marks the service as shutting down, service is never ready after that so that no new requests are sent to it
+func (c *Controller) ShutDown() {
+	atomic.StoreInt32(&c.shuttingDown, 1)
+}
+
+// CheckReadiness checks every component the service depends on i.e. connectivity of the cache, its circuit breaker
+// and the stored flight schedules, which are only required if the service is configured to require them
+// cache is not required by default, as searches are calculated without it, so that a cache outage does not take every instance out of rotation
+func (c *Controller) CheckReadiness() *status.Readiness {
+	if atomic.LoadInt32(&c.shuttingDown) == 1 {
+		return &status.Readiness{Status: literals.ShuttingDown}
+	}
+
+	readiness := &status.Readiness{
+		Status: literals.Ready,
+		Components: map[string]*status.Component{
+			literals.Cache:          checkComponent(constants.Env.ReadinessRequiresCache, c.checkCache),
+			literals.CircuitBreaker: checkComponent(constants.Env.ReadinessRequiresCache, c.checkCircuitBreaker),
+			literals.Timetable:      checkComponent(constants.Env.ReadinessRequiresTimetable, c.checkTimetable),
+		},
+	}
+	for _, component := range readiness.Components {
+		if component.Required && component.Status != literals.Up {
+			readiness.Status = literals.NotReady
+		}
+	}
+	return readiness
+}
+
+// checkComponent checks a component by given check, which gets details of the component or error if the component is down
+func checkComponent(required bool, check func() (string, error)) *status.Component {
+	start := time.Now()
+	details, err := check()
+	component := &status.Component{
+		Status:    literals.Up,
+		Required:  required,
+		LatencyMS: float64(time.Since(start)) / float64(time.Millisecond),
+		Details:   details,
+	}
+	if err != nil {
+		component.Status = literals.Down
+		component.Error = err.Error()
+	}
+	return component
+}
+
+// checkCache checks if the cache is reachable, redis is not dialed while its circuit breaker is open and ping of redis has a deadline
+func (c *Controller) checkCache() (string, error) {
+	return "", c.Dao.Cache.Ping()
+}
+
+// checkCircuitBreaker checks if the circuit breaker of the cache is closed i.e. cache is used by the requests
+func (c *Controller) checkCircuitBreaker() (string, error) {
+	breakerStatus := cache.GetStatus(c.Dao.Cache)
+	if breakerStatus.State != literals.BreakerClosed {
+		return breakerStatus.State, errors.New("circuit breaker is open since " + breakerStatus.OpenedAt.Format(time.RFC3339))
+	}
+	return breakerStatus.State, nil
+}
+
+// checkTimetable checks if any flight schedule is stored, which are routed against when a request does not have schedules
+func (c *Controller) checkTimetable() (string, error) {
+	count, err := c.Dao.ScheduleModel.Count()
+	if err != nil {
+		return "", err
+	}
+	details := strconv.Itoa(count) + " stored flight schedules"
+	if count == 0 {
+		return details, errors.New("no flight schedule is stored")
+	}
+	return details, nil
+}
@@ -0,0 +1,116 @@
+package status
+
+import (
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/somprabhsharma/the-lazy-traveler/constants"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+	"github.com/somprabhsharma/the-lazy-traveler/models"
+	"github.com/somprabhsharma/the-lazy-traveler/models/cache"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStatusController(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "The Lazy Traveler Suite")
+}
+
+var _ = Describe("controllers", func() {
+	Context("##status", func() {
+		env := constants.Env
+		AfterEach(func() {
+			constants.Env = env
+		})
+
+		It("should be ready with status of every component even if no flight schedule is stored", func() {
+			controller := NewController(models.NewDaoWithCache(cache.NewMemory(0)))
+			readiness := controller.CheckReadiness()
+			Expect(readiness.Status).To(Equal(literals.Ready))
+			Expect(readiness.Components).To(HaveLen(3))
+			Expect(readiness.Components[literals.Cache].Status).To(Equal(literals.Up))
+			Expect(readiness.Components[literals.CircuitBreaker].Status).To(Equal(literals.Up))
+			Expect(readiness.Components[literals.CircuitBreaker].Details).To(Equal(literals.BreakerClosed))
+
+			timetable := readiness.Components[literals.Timetable]
+			Expect(timetable.Status).To(Equal(literals.Down))
+			Expect(timetable.Required).To(BeFalse())
+			Expect(timetable.Error).To(Equal("no flight schedule is stored"))
+		})
+
+		It("should not be ready without stored flight schedules if they are required", func() {
+			constants.Env.ReadinessRequiresTimetable = true
+			dao := models.NewDaoWithCache(cache.NewMemory(0))
+			controller := NewController(dao)
+			Expect(controller.CheckReadiness().Status).To(Equal(literals.NotReady))
+
+			_ = dao.ScheduleModel.Put(&flightpath.FlightDetail{
+				ID:        "test-flight-123",
+				Departure: &flightpath.ScheduleDetail{City: "A", Timestamp: 1},
+				Arrival:   &flightpath.ScheduleDetail{City: "Z", Timestamp: 10},
+			})
+			readiness := controller.CheckReadiness()
+			Expect(readiness.Status).To(Equal(literals.Ready))
+			Expect(readiness.Components[literals.Timetable].Details).To(Equal("1 stored flight schedules"))
+		})
+
+		It("should be ready if cache is not reachable and its circuit breaker is open unless cache is required", func() {
+			ping := func() error {
+				return errors.New("cache is down")
+			}
+			controller := NewController(models.NewDaoWithCache(cache.NewBreaker(cache.NewMemory(0), ping, 1, time.Hour, 0)))
+			readiness := controller.CheckReadiness()
+			Expect(readiness.Status).To(Equal(literals.Ready))
+			Expect(readiness.Components[literals.Cache].Error).To(Equal("cache is down"))
+			Expect(readiness.Components[literals.Cache].Required).To(BeFalse())
+			Expect(readiness.Components[literals.CircuitBreaker].Status).To(Equal(literals.Down))
+			Expect(readiness.Components[literals.CircuitBreaker].Required).To(BeFalse())
+			Expect(readiness.Components[literals.Timetable].Error).To(Equal(cache.ErrUnavailable.Error()))
+
+			constants.Env.ReadinessRequiresCache = true
+			readiness = controller.CheckReadiness()
+			Expect(readiness.Status).To(Equal(literals.NotReady))
+			Expect(readiness.Components[literals.Cache].Required).To(BeTrue())
+			Expect(readiness.Components[literals.CircuitBreaker].Required).To(BeTrue())
+		})
+
+		It("should not ping the cache while its circuit breaker is open and should not wait for a slow ping", func() {
+			var pings int32
+			var delay int64
+			ping := func() error {
+				atomic.AddInt32(&pings, 1)
+				time.Sleep(time.Duration(atomic.LoadInt64(&delay)))
+				return nil
+			}
+			breaker := cache.NewBreaker(cache.NewMemory(0), ping, 1, time.Hour, 50*time.Millisecond)
+			controller := NewController(models.NewDaoWithCache(breaker))
+			Expect(controller.CheckReadiness().Components[literals.Cache].Status).To(Equal(literals.Up))
+
+			atomic.StoreInt64(&delay, int64(time.Second))
+			start := time.Now()
+			readiness := controller.CheckReadiness()
+			Expect(readiness.Components[literals.Cache].Error).To(Equal(cache.ErrTimeout.Error()))
+			Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond))
+
+			openBreaker := cache.NewBreaker(cache.NewMemory(0), func() error {
+				atomic.AddInt32(&pings, 1)
+				return errors.New("cache is down")
+			}, 1, time.Hour, 0)
+			calls := atomic.LoadInt32(&pings)
+			readiness = NewController(models.NewDaoWithCache(openBreaker)).CheckReadiness()
+			Expect(readiness.Components[literals.Cache].Error).To(Equal("cache is down"))
+			Expect(atomic.LoadInt32(&pings)).To(Equal(calls))
+		})
+
+		It("should not be ready once it is shutting down", func() {
+			controller := NewController(models.NewDaoWithCache(cache.NewMemory(0)))
+			controller.ShutDown()
+			readiness := controller.CheckReadiness()
+			Expect(readiness.Status).To(Equal(literals.ShuttingDown))
+			Expect(readiness.Components).To(BeEmpty())
+		})
+	})
+})
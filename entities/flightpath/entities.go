@@ -1,26 +1,169 @@
 package flightpath
 
+import (
+	"github.com/somprabhsharma/the-lazy-traveler/constants/errorconsts"
+)
+
 // LazyJackRequest is struct of body for lazy jack api
 type LazyJackRequest struct {
 	PreferredTime int64           `json:"preferred_time,omitempty"`
-	TripPlan      *TripDetail     `json:"trip_plan" binding:"required"`
-	Schedules     []*FlightDetail `json:"schedules" binding:"required"`
+	TripPlan      *TripDetail     `json:"trip_plan"`           // required, except in batch request which has TripPlans instead
+	Schedules     []*FlightDetail `json:"schedules,omitempty"` // stored flight schedules are used if schedules are not provided
+	K             int             `json:"k,omitempty" binding:"omitempty,min=1,max=20"`
+	Objective     string          `json:"objective,omitempty"`
+	ArriveBy      int64           `json:"arrive_by,omitempty"`
+	Engine        string          `json:"engine,omitempty"`  // routing engine used for the search, all the engines find same flight paths
+	Explain       bool            `json:"explain,omitempty"` // returns explanation of the search along with the itineraries
+
+	// connection constraints, they apply to every connecting city unless overridden in ConnectionRules for the city
+	MinConnectionTime int64                      `json:"min_connection_time,omitempty"`
+	MaxLayover        int64                      `json:"max_layover,omitempty"`
+	ConnectionRules   map[string]*ConnectionRule `json:"connection_rules,omitempty"`
+
+	// search constraints, they are applied to every segment of the trip
+	MaxStops          *int     `json:"max_stops,omitempty"`          // maximum number of stops, zero means direct flights only
+	DirectOnly        bool     `json:"direct_only,omitempty"`        // same as zero MaxStops
+	ExcludedCities    []string `json:"excluded_cities,omitempty"`    // cities which cannot be used as connection points
+	ExcludedCarriers  []string `json:"excluded_carriers,omitempty"`  // carriers whose flights cannot be taken
+	PreferredCarriers []string `json:"preferred_carriers,omitempty"` // carriers whose flights are preferred over otherwise equal flights
+
+	// RoundTrip searches return from end city back to start city along with the trip plan
+	RoundTrip *RoundTrip `json:"round_trip,omitempty"`
+
+	// TimeZones are IANA time zones of the cities e.g. {"NYC": "America/New_York"}, which are needed for LocalTime of schedules
+	TimeZones map[string]string `json:"time_zones,omitempty"`
+
+	// StoredSchedulesVersion is version of the stored flight schedules loaded in Schedules, nil if schedules are provided in the request
+	// it is never read from the request, stored schedules are identified by their version in the cache key instead of their content
+	StoredSchedulesVersion *int64 `json:"-"`
+}
+
+// BatchRequest is struct of body for batch lazy jack api
+// all the trip plans are searched over the same flight schedules with the same parameters of the embedded request
+type BatchRequest struct {
+	LazyJackRequest
+	TripPlans []*TripDetail `json:"trip_plans" binding:"required"`
+}
+
+// BatchResult is the result of a trip plan of the batch request, it has either the itineraries or the error of the trip plan
+type BatchResult struct {
+	TripPlan    *TripDetail          `json:"trip_plan"`
+	Itineraries []*Itinerary         `json:"itineraries,omitempty"`
+	Error       *errorconsts.LTError `json:"error,omitempty"`
+}
+
+// RoundTrip is the details of the return journey of a round trip
+type RoundTrip struct {
+	OutboundWindow *TimeWindow `json:"outbound_window,omitempty"` // window in which outbound itinerary departs
+	ReturnWindow   *TimeWindow `json:"return_window,omitempty"`   // window in which return itinerary departs
+
+	// stay at the end city between outbound arrival and return departure, zero MaxStay means there is no limit
+	MinStay int64 `json:"min_stay,omitempty"`
+	MaxStay int64 `json:"max_stay,omitempty"`
+}
+
+// TimeWindow is a window of time with inclusive bounds, zero value of a bound means there is no such bound
+type TimeWindow struct {
+	From int64 `json:"from,omitempty"`
+	To   int64 `json:"to,omitempty"`
+}
+
+// ConnectionRule is the connection constraint of a city, zero value of a field means the request level value is used
+type ConnectionRule struct {
+	MinConnectionTime int64 `json:"min_connection_time,omitempty"`
+	MaxLayover        int64 `json:"max_layover,omitempty"`
 }
 
 // TripDetail is the details of the trip i.e. start, end city
 type TripDetail struct {
 	StartCity string `json:"start_city" binding:"required"`
 	EndCity   string `json:"end_city" binding:"required"`
+	// Waypoints are the cities visited in the given order between start and end city, each of them starts a new segment of the trip
+	Waypoints []*Waypoint `json:"waypoints,omitempty"`
+}
+
+// Waypoint is a city visited during a multi city trip
+type Waypoint struct {
+	City    string `json:"city"`
+	MinStay int64  `json:"min_stay,omitempty"` // minimum time to stay in the city before the next segment departs
 }
 
 // ScheduleDetail is the schedule detail of a flight either arrival or departure schedule
 type ScheduleDetail struct {
 	City      string `json:"city" binding:"required"`
-	Timestamp int64  `json:"timestamp" binding:"required"`
+	Timestamp int64  `json:"timestamp,omitempty"`
+
+	// Time is ISO-8601 date time with offset, it can be given instead of Timestamp
+	// LocalTime is ISO-8601 date time without offset in the time zone of the city, it can be given instead of Timestamp
+	// in the flight plans returned by the apis, LocalTime has the offset of the time zone of the city
+	Time      string `json:"time,omitempty"`
+	LocalTime string `json:"local_time,omitempty"`
+	// UTCTime is ISO-8601 date time in UTC, it is only set in the flight plans returned by the apis
+	UTCTime string `json:"utc_time,omitempty"`
+
+	// Flight is the flight which arrives at this schedule, it is only set in the flight plans returned by the apis
+	Flight *FlightIdentity `json:"flight,omitempty"`
 }
 
 // FlightDetail is the flight details i.e arrival, departure details
 type FlightDetail struct {
-	Departure *ScheduleDetail `json:"departure" binding:"required"`
-	Arrival   *ScheduleDetail `json:"arrival" binding:"required"`
+	ID           string          `json:"id,omitempty"`
+	FlightNumber string          `json:"flight_number,omitempty"`
+	Carrier      string          `json:"carrier,omitempty"`
+	Aircraft     string          `json:"aircraft,omitempty"`
+	Fare         *Fare           `json:"fare,omitempty"`
+	Departure    *ScheduleDetail `json:"departure" binding:"required"`
+	Arrival      *ScheduleDetail `json:"arrival" binding:"required"`
+}
+
+// Fare is the price of a flight
+type Fare struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency" binding:"required"`
+}
+
+// FlightIdentity is the identity of a bookable flight
+type FlightIdentity struct {
+	ID           string `json:"id,omitempty"`
+	FlightNumber string `json:"flight_number,omitempty"`
+	Carrier      string `json:"carrier,omitempty"`
+	Aircraft     string `json:"aircraft,omitempty"`
+}
+
+// Itinerary is a flight plan made of legs along with its totals
+type Itinerary struct {
+	Legs          []*Leg `json:"legs"`
+	TotalDuration int64  `json:"total_duration"`
+	InAirTime     int64  `json:"in_air_time"`
+	LayoverTime   int64  `json:"layover_time"`
+	Stops         int    `json:"stops"`
+	TotalFare     *Fare  `json:"total_fare,omitempty"` // sum of fares of the legs, nil if no leg has a fare
+
+	// Segments are the itineraries between consecutive waypoints of a multi city trip, legs of the itinerary are legs of all the segments
+	Segments []*Itinerary `json:"segments,omitempty"`
+}
+
+// Explanation is the trace of the search which explains how the itineraries were chosen
+type Explanation struct {
+	Engine            string       `json:"engine"`
+	NodesExpanded     int          `json:"nodes_expanded"`     // number of times paths were extended from an arrival at a city
+	ConnectionsPruned int          `json:"connections_pruned"` // connecting flights skipped because they had already departed
+	Candidates        []*Candidate `json:"candidates"`         // best ranked itineraries which reached end city, including the chosen ones
+}
+
+// Candidate is an itinerary which reached end city during the search
+type Candidate struct {
+	Itinerary      *Itinerary `json:"itinerary"`
+	Selected       bool       `json:"selected"`
+	RejectedReason string     `json:"rejected_reason,omitempty"`
+}
+
+// Leg is a single flight of an itinerary along with the layover before the next leg
+type Leg struct {
+	Departure      ScheduleDetail  `json:"departure"`
+	Arrival        ScheduleDetail  `json:"arrival"`
+	Flight         *FlightIdentity `json:"flight,omitempty"`
+	Fare           *Fare           `json:"fare,omitempty"`
+	FlightDuration int64           `json:"flight_duration"`
+	Layover        int64           `json:"layover"`
 }
@@ -0,0 +1,17 @@
+package status
+
+// Readiness is the readiness of the service along with status of every component it depends on
+// service is ready only if it is not shutting down and all of its required components are up
+type Readiness struct {
+	Status     string                `json:"status"`
+	Components map[string]*Component `json:"components,omitempty"`
+}
+
+// Component is the status of a component the service depends on, as found by checking it
+type Component struct {
+	Status    string  `json:"status"`
+	Required  bool    `json:"required"`          // service is not ready if a required component is down
+	LatencyMS float64 `json:"latency_ms"`        // time taken to check the component in milliseconds
+	Details   string  `json:"details,omitempty"` // details of the component e.g. number of stored flight schedules
+	Error     string  `json:"error,omitempty"`   // why the component is down
+}
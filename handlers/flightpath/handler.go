@@ -8,19 +8,23 @@ import (
 	"github.com/somprabhsharma/the-lazy-traveler/controllers/flightpath"
 	entities "github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
 	"github.com/somprabhsharma/the-lazy-traveler/models"
+	"github.com/somprabhsharma/the-lazy-traveler/models/catalog"
 	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
 	"net/http"
+	"strconv"
 )
 
 // Handler is a struct which will act like a handler for flight path related APIs
 type Handler struct {
 	flightPathController flightpath.Controller
+	cityCatalog          *catalog.Catalog
 }
 
 // NewHandler is a constructor for Handler struct
 func NewHandler(dao *models.Dao) *Handler {
 	return &Handler{
 		flightPathController: *flightpath.NewController(dao),
+		cityCatalog:          dao.CityCatalog,
 	}
 }
 
@@ -35,7 +39,19 @@ func (h *Handler) FindShortestFlightPath(c *gin.Context) {
 	body, _ := v.(entities.LazyJackRequest)
 	logger.Info(literals.LazyJack, "Request received to find shortest flight path with data", body)
 
-	shortestPath, err := h.flightPathController.FindShortestFlightPath(body)
+	// return top k flight paths if k is asked in the request
+	if body.K > 0 {
+		flightPaths, err := h.flightPathController.FindFlightPaths(c.Request.Context(), body)
+		if err != nil {
+			logger.Err(literals.LazyJack, "Error while finding shortest flight paths", err, body)
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{literals.FlightPlans: flightPaths})
+		return
+	}
+
+	shortestPath, err := h.flightPathController.FindShortestFlightPath(c.Request.Context(), body)
 	if err != nil {
 		logger.Err(literals.LazyJack, "Error while finding shortest flight path", err, body)
 		_ = c.AbortWithError(http.StatusBadRequest, err)
@@ -43,3 +59,56 @@ func (h *Handler) FindShortestFlightPath(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{literals.FlightPlan: shortestPath})
 }
+
+// FindItineraries finds shortest itineraries with separate legs and their totals
+func (h *Handler) FindItineraries(c *gin.Context) {
+	v, ok := c.Get("lazyJackRequest")
+	if !ok {
+		_ = c.AbortWithError(http.StatusBadRequest, errors.New(errorconsts.InvalidRequest))
+		return
+	}
+
+	body, _ := v.(entities.LazyJackRequest)
+	logger.Info(literals.LazyJack, "Request received to find shortest itineraries with data", body)
+
+	// explanation of the search is returned along with the itineraries if asked
+	if body.Explain {
+		itineraries, explanation, err := h.flightPathController.ExplainItineraries(c.Request.Context(), body)
+		if err != nil {
+			logger.Err(literals.LazyJack, "Error while explaining shortest itineraries", err, body)
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{literals.Itineraries: itineraries, literals.Explanation: explanation})
+		return
+	}
+
+	itineraries, err := h.flightPathController.FindItineraries(c.Request.Context(), body)
+	if err != nil {
+		logger.Err(literals.LazyJack, "Error while finding shortest itineraries", err, body)
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{literals.Itineraries: itineraries})
+}
+
+// FindBatchItineraries finds shortest itineraries of many trip plans over the same flight schedules
+// response has result of every trip plan in the order of the request, a trip plan which cannot be searched has an error in its result
+func (h *Handler) FindBatchItineraries(c *gin.Context) {
+	v, ok := c.Get("batchRequest")
+	if !ok {
+		_ = c.AbortWithError(http.StatusBadRequest, errors.New(errorconsts.InvalidRequest))
+		return
+	}
+
+	body, _ := v.(entities.BatchRequest)
+	logger.Info(literals.LazyJack, "Request received to find shortest itineraries of "+strconv.Itoa(len(body.TripPlans))+" trip plans", nil)
+
+	results, err := h.flightPathController.FindBatchItineraries(c.Request.Context(), body)
+	if err != nil {
+		logger.Err(literals.LazyJack, "Error while finding shortest itineraries of trip plans", err, nil)
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{literals.Results: results})
+}
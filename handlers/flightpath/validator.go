@@ -11,13 +11,140 @@ import (
 )
 
 // ValidateLazyJackRequest validate request body in lazy jack apis by trying to bind it
+// cities of the request are normalized against the city catalog, so that codes, names & aliases of a city are treated alike
 func (h *Handler) ValidateLazyJackRequest(c *gin.Context) {
 	var lazyJackRequest flightpath.LazyJackRequest
 
-	if err := c.Bind(&lazyJackRequest); err != nil {
+	if err := c.Bind(&lazyJackRequest); err != nil || lazyJackRequest.TripPlan == nil {
 		logger.Err(literals.LazyJack, "error in binding request", err, lazyJackRequest)
 		_ = c.AbortWithError(http.StatusBadRequest, errors.New(errorconsts.InvalidRequest))
+		return
+	}
+
+	err := h.normalizeTripPlan(lazyJackRequest.TripPlan)
+	if err == nil {
+		err = h.normalizeCities(&lazyJackRequest)
+	}
+	if err != nil {
+		logger.Err(literals.LazyJack, "error in normalizing cities of request", err, lazyJackRequest)
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
 	}
 
 	c.Set("lazyJackRequest", lazyJackRequest)
 }
+
+// ValidateBatchRequest validate request body in batch lazy jack api by trying to bind it
+// batch request has trip plans instead of a trip plan, every trip plan must have start and end city
+func (h *Handler) ValidateBatchRequest(c *gin.Context) {
+	var batchRequest flightpath.BatchRequest
+
+	if err := c.Bind(&batchRequest); err != nil {
+		logger.Err(literals.LazyJack, "error in binding batch request", err, batchRequest)
+		_ = c.AbortWithError(http.StatusBadRequest, errors.New(errorconsts.InvalidRequest))
+		return
+	}
+
+	for _, tripPlan := range batchRequest.TripPlans {
+		if tripPlan == nil || tripPlan.StartCity == "" || tripPlan.EndCity == "" {
+			logger.Err(literals.LazyJack, "error in binding batch request", errors.New(errorconsts.InvalidRequest), batchRequest)
+			_ = c.AbortWithError(http.StatusBadRequest, errors.New(errorconsts.InvalidRequest))
+			return
+		}
+	}
+
+	// trip plans which are not in the catalog of a strict catalog fail the whole batch, like any other city of the request
+	var err error
+	for _, tripPlan := range batchRequest.TripPlans {
+		err = h.normalizeTripPlan(tripPlan)
+		if err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = h.normalizeCities(&batchRequest.LazyJackRequest)
+	}
+	if err != nil {
+		logger.Err(literals.LazyJack, "error in normalizing cities of batch request", err, batchRequest)
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	c.Set("batchRequest", batchRequest)
+}
+
+// normalizeTripPlan normalizes start, end city and waypoints of the trip plan against the city catalog
+func (h *Handler) normalizeTripPlan(tripPlan *flightpath.TripDetail) error {
+	var err error
+	tripPlan.StartCity, err = h.cityCatalog.Normalize(tripPlan.StartCity)
+	if err != nil {
+		return err
+	}
+	tripPlan.EndCity, err = h.cityCatalog.Normalize(tripPlan.EndCity)
+	if err != nil {
+		return err
+	}
+	for _, waypoint := range tripPlan.Waypoints {
+		if waypoint == nil {
+			return errors.New(errorconsts.InvalidWaypoint)
+		}
+		waypoint.City, err = h.cityCatalog.Normalize(waypoint.City)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeCities normalizes flight schedules, excluded cities, connection rules and time zones of the request against the city catalog
+func (h *Handler) normalizeCities(data *flightpath.LazyJackRequest) error {
+	var err error
+	for _, schedule := range data.Schedules {
+		if schedule == nil {
+			return errors.New(errorconsts.InvalidFlightSchedule)
+		}
+		err = h.cityCatalog.NormalizeSchedule(schedule)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, city := range data.ExcludedCities {
+		data.ExcludedCities[i], err = h.cityCatalog.Normalize(city)
+		if err != nil {
+			return err
+		}
+	}
+
+	// different names of same city cannot have different connection rules or time zones
+	if data.ConnectionRules != nil {
+		connectionRules := make(map[string]*flightpath.ConnectionRule, len(data.ConnectionRules))
+		for city, rule := range data.ConnectionRules {
+			city, err = h.cityCatalog.Normalize(city)
+			if err != nil {
+				return err
+			}
+			if _, ok := connectionRules[city]; ok {
+				return errors.New(errorconsts.InvalidConnectionRule)
+			}
+			connectionRules[city] = rule
+		}
+		data.ConnectionRules = connectionRules
+	}
+
+	if data.TimeZones != nil {
+		timeZones := make(map[string]string, len(data.TimeZones))
+		for city, timeZone := range data.TimeZones {
+			city, err = h.cityCatalog.Normalize(city)
+			if err != nil {
+				return err
+			}
+			if _, ok := timeZones[city]; ok {
+				return errors.New(errorconsts.InvalidTimeZone)
+			}
+			timeZones[city] = timeZone
+		}
+		data.TimeZones = timeZones
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package schedule
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/errorconsts"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"github.com/somprabhsharma/the-lazy-traveler/controllers/schedule"
+	entities "github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+	"github.com/somprabhsharma/the-lazy-traveler/models"
+	"github.com/somprabhsharma/the-lazy-traveler/models/catalog"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
+	"net/http"
+)
+
+// Handler is a struct which will act like a handler for flight schedule related APIs
+type Handler struct {
+	scheduleController schedule.Controller
+	cityCatalog        *catalog.Catalog
+}
+
+// NewHandler is a constructor for Handler struct
+func NewHandler(dao *models.Dao) *Handler {
+	return &Handler{
+		scheduleController: *schedule.NewController(dao),
+		cityCatalog:        dao.CityCatalog,
+	}
+}
+
+// CreateSchedule saves a new flight schedule in the store
+func (h *Handler) CreateSchedule(c *gin.Context) {
+	v, ok := c.Get("scheduleRequest")
+	if !ok {
+		_ = c.AbortWithError(http.StatusBadRequest, errors.New(errorconsts.InvalidRequest))
+		return
+	}
+
+	body, _ := v.(entities.FlightDetail)
+	logger.Info(literals.ScheduleStore, "Request received to create flight schedule with data", body)
+
+	createdSchedule, err := h.scheduleController.CreateSchedule(body)
+	if err != nil {
+		logger.Err(literals.ScheduleStore, "Error while creating flight schedule", err, body)
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{literals.Schedule: createdSchedule})
+}
+
+// ListSchedules lists all the stored flight schedules
+func (h *Handler) ListSchedules(c *gin.Context) {
+	schedules, err := h.scheduleController.ListSchedules()
+	if err != nil {
+		logger.Err(literals.ScheduleStore, "Error while listing flight schedules", err, nil)
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{literals.Schedules: schedules})
+}
+
+// UpdateSchedule replaces the stored flight schedule with id given in the url
+func (h *Handler) UpdateSchedule(c *gin.Context) {
+	v, ok := c.Get("scheduleRequest")
+	if !ok {
+		_ = c.AbortWithError(http.StatusBadRequest, errors.New(errorconsts.InvalidRequest))
+		return
+	}
+
+	id := c.Param("id")
+	body, _ := v.(entities.FlightDetail)
+	logger.Info(literals.ScheduleStore, "Request received to update flight schedule "+id+" with data", body)
+
+	updatedSchedule, err := h.scheduleController.UpdateSchedule(id, body)
+	if err != nil {
+		logger.Err(literals.ScheduleStore, "Error while updating flight schedule "+id, err, body)
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{literals.Schedule: updatedSchedule})
+}
+
+// DeleteSchedule deletes the stored flight schedule with id given in the url
+func (h *Handler) DeleteSchedule(c *gin.Context) {
+	id := c.Param("id")
+	logger.Info(literals.ScheduleStore, "Request received to delete flight schedule "+id, nil)
+
+	err := h.scheduleController.DeleteSchedule(id)
+	if err != nil {
+		logger.Err(literals.ScheduleStore, "Error while deleting flight schedule "+id, err, nil)
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
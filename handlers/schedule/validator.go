@@ -0,0 +1,31 @@
+package schedule
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/errorconsts"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
+	"net/http"
+)
+
+// ValidateScheduleRequest validate request body in schedule apis by trying to bind it
+// cities of the flight schedule are normalized against the city catalog, so that stored schedules match normalized lazy jack requests
+func (h *Handler) ValidateScheduleRequest(c *gin.Context) {
+	var scheduleRequest flightpath.FlightDetail
+
+	if err := c.Bind(&scheduleRequest); err != nil {
+		logger.Err(literals.ScheduleStore, "error in binding request", err, scheduleRequest)
+		_ = c.AbortWithError(http.StatusBadRequest, errors.New(errorconsts.InvalidRequest))
+		return
+	}
+
+	if err := h.cityCatalog.NormalizeSchedule(&scheduleRequest); err != nil {
+		logger.Err(literals.ScheduleStore, "error in normalizing cities of request", err, scheduleRequest)
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	c.Set("scheduleRequest", scheduleRequest)
+}
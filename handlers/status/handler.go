@@ -0,0 +1,52 @@
+package status
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"github.com/somprabhsharma/the-lazy-traveler/controllers/status"
+	"github.com/somprabhsharma/the-lazy-traveler/models"
+	"github.com/somprabhsharma/the-lazy-traveler/models/cache"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
+	"net/http"
+)
+
+// Handler is a struct which will act like a handler for status related APIs
+type Handler struct {
+	statusController *status.Controller
+	cache            cache.Cache
+}
+
+// NewHandler is a constructor for Handler struct
+func NewHandler(dao *models.Dao) *Handler {
+	return &Handler{
+		statusController: status.NewController(dao),
+		cache:            dao.Cache,
+	}
+}
+
+// ShutDown marks the service as shutting down, readiness fails from then on
+func (h *Handler) ShutDown() {
+	logger.Info(literals.Status, "Service is shutting down, it is not ready anymore", nil)
+	h.statusController.ShutDown()
+}
+
+// Healthz tells that the process is alive, it does not check any component as the process need not be restarted if they are down
+func (h *Handler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{literals.Status: literals.Alive})
+}
+
+// Readyz tells if the service is ready to serve requests along with status of every component it depends on
+func (h *Handler) Readyz(c *gin.Context) {
+	readiness := h.statusController.CheckReadiness()
+	if readiness.Status != literals.Ready {
+		logger.Info(literals.Status, "Service is not ready", readiness)
+		c.JSON(http.StatusServiceUnavailable, readiness)
+		return
+	}
+	c.JSON(http.StatusOK, readiness)
+}
+
+// CacheStatus gets the state of the circuit breaker of the cache i.e. whether the cache is used or skipped as it is unhealthy
+func (h *Handler) CacheStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{literals.Cache: cache.GetStatus(h.cache)})
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/somprabhsharma/the-lazy-traveler/middlewares"
 	"github.com/somprabhsharma/the-lazy-traveler/models"
 	"github.com/somprabhsharma/the-lazy-traveler/routes/api"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/server"
 	"log"
 	"net/http"
 )
@@ -24,14 +25,25 @@ func main() {
 		c.String(http.StatusOK, "Pong!")
 	})
 
-	// initialize dao layer
-	dao := models.NewDao()
+	// initialize dao layer, server does not start if the cache is not configured properly
+	dao, err := models.NewDao()
+	if err != nil {
+		log.Fatal("Unable to initialize dao layer: ", err)
+	}
 
 	// register api routes
-	api.Register(router, dao)
+	statusHandler := api.Register(router, dao)
 
-	err := router.Run(":" + constants.Env.Port)
+	// server drains in flight requests on SIGINT or SIGTERM, readiness fails as soon as it starts shutting down
+	srv := server.New(":"+constants.Env.Port, router, constants.Env.ShutdownTimeout, constants.Env.ShutdownDrainDelay)
+	srv.BeforeShutdown(statusHandler.ShutDown)
+	err = srv.ListenAndServe()
+
+	// cache is closed only after requests are drained, so that in flight searches can still write their results
+	if closeErr := dao.Close(); closeErr != nil {
+		log.Println("Unable to close cache: ", closeErr)
+	}
 	if err != nil {
-		log.Fatal("Unable to start server")
+		log.Fatal("Server stopped with error: ", err)
 	}
 }
@@ -0,0 +1,14 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecate marks every response of the routes as deprecated and points clients to the successor version
+func Deprecate(successorURL string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Set("Deprecation", "true")
+		c.Writer.Header().Set("Link", "<"+successorURL+">; rel=\"successor-version\"")
+		c.Next()
+	}
+}
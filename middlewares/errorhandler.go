@@ -6,7 +6,6 @@ import (
 	"github.com/somprabhsharma/the-lazy-traveler/constants/errorconsts"
 	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
 	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
-	"net/http"
 	"strconv"
 )
 
@@ -26,28 +25,8 @@ func HandleErrors(c *gin.Context) {
 	}
 	err := c.Errors.Last()
 
-	// default ltError
-	ltError := errorconsts.LTError{
-		Message:  errorconsts.GenericErrorMessage,
-		Code:     errorconsts.GenericErrorCode,
-		HTTPCode: http.StatusBadRequest,
-		Err:      err.Error(),
-	}
-
-	// if ltErrorMap have the mapping for the received error
-	// assign the corresponding ltError to override default
-	if ltErr, ok := errorconsts.LTErrorMap[err.Error()]; ok {
-		ltError = ltErr
-
-		// assign default values to missing fields
-		if ltError.HTTPCode == 0 {
-			ltError.HTTPCode = http.StatusBadRequest
-		}
-
-		if ltError.Err == "" {
-			ltError.Err = err.Error()
-		}
-	}
+	// if ltErrorMap have the mapping for the received error, corresponding ltError is used instead of the generic one
+	ltError := errorconsts.GetLTError(err)
 
 	logger.Info(literals.LazyJack, "Code: "+strconv.Itoa(ltError.Code)+" Message: "+ltError.Message+" Error: "+ltError.Err, nil)
 
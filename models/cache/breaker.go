@@ -0,0 +1,324 @@
+package cache
+
+import (
+	"errors"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/errorconsts"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
+	"sync"
+	"time"
+)
+
+// ErrUnavailable is returned instead of calling the cache while the cache is unhealthy
+var ErrUnavailable = errors.New(errorconsts.CacheUnavailable)
+
+// ErrTimeout is returned when the cache does not respond within the call timeout, it is reported same as unhealthy cache
+var ErrTimeout = errors.New(errorconsts.CacheUnavailable)
+
+// Status is the state of the circuit breaker of the cache
+type Status struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+}
+
+// Breaker is a circuit breaker around a cache, which skips the cache while it is unhealthy
+// breaker opens once calls to the cache fail consecutively for max failures times, calls fail with ErrUnavailable without reaching
+// the cache while it is open, meanwhile the cache is probed in background and breaker closes as soon as a probe succeeds
+// every call fails with ErrTimeout once call timeout passes, so that an unreachable cache delays a request by at most the call timeout
+// while the client of the cache is still retrying, and breaker opens within max failures times the call timeout
+type Breaker struct {
+	Cache
+	ping          func() error
+	maxFailures   int
+	probeInterval time.Duration
+	callTimeout   time.Duration // zero means calls do not have any deadline
+
+	mu       sync.Mutex
+	failures int // number of consecutive failed calls
+	open     bool
+	openedAt time.Time
+	lastErr  error
+	closed   bool // cache is closed, hence it is not probed anymore
+}
+
+// NewBreaker creates a circuit breaker around the cache, ping checks if the cache is healthy
+// cache is probed once at the start within the call timeout, so that breaker starts open without delaying the start if the cache is not reachable
+func NewBreaker(c Cache, ping func() error, maxFailures int, probeInterval, callTimeout time.Duration) *Breaker {
+	b := &Breaker{
+		Cache:         c,
+		ping:          ping,
+		maxFailures:   maxFailures,
+		probeInterval: probeInterval,
+		callTimeout:   callTimeout,
+	}
+	if err := b.withTimeout(ping); err != nil {
+		b.mu.Lock()
+		b.lastErr = err
+		b.openBreaker()
+		b.mu.Unlock()
+	}
+	return b
+}
+
+// Status gets the state of the breaker
+func (b *Breaker) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := Status{State: literals.BreakerClosed, ConsecutiveFailures: b.failures}
+	if b.open {
+		openedAt := b.openedAt
+		status.State = literals.BreakerOpen
+		status.OpenedAt = &openedAt
+	}
+	if b.lastErr != nil {
+		status.LastError = b.lastErr.Error()
+	}
+	return status
+}
+
+// allow tells if the cache can be called i.e. breaker is closed
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.open
+}
+
+// record records result of a call to the cache, missing key or field is a successful call
+func (b *Breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil || IsNotFound(err) {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	b.lastErr = err
+	if !b.open && b.failures >= b.maxFailures {
+		b.openBreaker()
+	}
+}
+
+// openBreaker opens the breaker and starts probing the cache in background, caller must hold the lock of the breaker
+func (b *Breaker) openBreaker() {
+	b.open = true
+	b.openedAt = time.Now()
+	logger.Err("Cache", "Circuit breaker opened, cache is skipped until it recovers", b.lastErr, b.failures)
+	go b.probe()
+}
+
+// probe pings the cache at every probe interval until it succeeds, then the breaker is closed
+func (b *Breaker) probe() {
+	for {
+		time.Sleep(b.probeInterval)
+		err := b.ping()
+
+		b.mu.Lock()
+		if b.closed {
+			b.mu.Unlock()
+			return
+		}
+		if err == nil {
+			b.open = false
+			b.failures = 0
+			b.mu.Unlock()
+			logger.Info("Cache", "Circuit breaker closed, cache has recovered", nil)
+			return
+		}
+		b.lastErr = err
+		b.mu.Unlock()
+	}
+}
+
+// Ping checks if the cache is reachable, it does not change state of the breaker
+// cache is not pinged while the breaker is open, as the probe is already pinging it, instead error of the last probe is returned
+// otherwise ping fails with ErrTimeout once call timeout passes, so that a check of the cache does not wait for the retries of its client
+func (b *Breaker) Ping() error {
+	b.mu.Lock()
+	open, lastErr := b.open, b.lastErr
+	b.mu.Unlock()
+	if open {
+		if lastErr != nil {
+			return lastErr
+		}
+		return ErrUnavailable
+	}
+	return b.withTimeout(b.ping)
+}
+
+// Close stops probing the cache and closes the cache
+func (b *Breaker) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	return b.Cache.Close()
+}
+
+// call calls the cache unless the breaker is open and records its result, call fails with ErrTimeout if it does not complete within call timeout
+func (b *Breaker) call(f func() error) error {
+	if !b.allow() {
+		return ErrUnavailable
+	}
+	err := b.withTimeout(f)
+	b.record(err)
+	return err
+}
+
+// withTimeout runs f and fails with ErrTimeout if it does not complete within call timeout
+// cache client cannot be interrupted, hence f which times out keeps running in background until the client gives up
+// f must not set anything read by the caller once it fails, as it can still be running
+func (b *Breaker) withTimeout(f func() error) error {
+	if b.callTimeout <= 0 {
+		return f()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- f()
+	}()
+
+	timer := time.NewTimer(b.callTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return ErrTimeout
+	}
+}
+
+// Put puts value corresponding to key unless the breaker is open
+func (b *Breaker) Put(key, value string, ttl time.Duration) error {
+	return b.call(func() error {
+		return b.Cache.Put(key, value, ttl)
+	})
+}
+
+// Get gets value for given key unless the breaker is open
+func (b *Breaker) Get(key string) (string, error) {
+	var value string
+	err := b.call(func() (err error) {
+		value, err = b.Cache.Get(key)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// Delete deletes value or hash stored at key unless the breaker is open
+func (b *Breaker) Delete(key string) error {
+	return b.call(func() error {
+		return b.Cache.Delete(key)
+	})
+}
+
+// PutIfAbsent puts value corresponding to key only if key is not present unless the breaker is open
+func (b *Breaker) PutIfAbsent(key, value string, ttl time.Duration) (bool, error) {
+	var put bool
+	err := b.call(func() (err error) {
+		put, err = b.Cache.PutIfAbsent(key, value, ttl)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return put, nil
+}
+
+// DeleteIfValue deletes value stored at key only if it is same as given value unless the breaker is open
+func (b *Breaker) DeleteIfValue(key, value string) (bool, error) {
+	var deleted bool
+	err := b.call(func() (err error) {
+		deleted, err = b.Cache.DeleteIfValue(key, value)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return deleted, nil
+}
+
+// PutField puts value corresponding to field of the hash stored at key unless the breaker is open
+func (b *Breaker) PutField(key, field, value string) error {
+	return b.call(func() error {
+		return b.Cache.PutField(key, field, value)
+	})
+}
+
+// PutFieldIfPresent puts value corresponding to field of the hash stored at key only if field is present unless the breaker is open
+func (b *Breaker) PutFieldIfPresent(key, field, value string) (bool, error) {
+	var put bool
+	err := b.call(func() (err error) {
+		put, err = b.Cache.PutFieldIfPresent(key, field, value)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return put, nil
+}
+
+// GetVersion gets version of the hash stored at key unless the breaker is open
+func (b *Breaker) GetVersion(key string) (int64, error) {
+	var version int64
+	err := b.call(func() (err error) {
+		version, err = b.Cache.GetVersion(key)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// GetField gets value of the field of the hash stored at key unless the breaker is open
+func (b *Breaker) GetField(key, field string) (string, error) {
+	var value string
+	err := b.call(func() (err error) {
+		value, err = b.Cache.GetField(key, field)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// GetAllFields gets all the fields and their values of the hash stored at key unless the breaker is open
+func (b *Breaker) GetAllFields(key string) (map[string]string, error) {
+	var values map[string]string
+	err := b.call(func() (err error) {
+		values, err = b.Cache.GetAllFields(key)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// DeleteField deletes the field of the hash stored at key unless the breaker is open
+func (b *Breaker) DeleteField(key, field string) (bool, error) {
+	var deleted bool
+	err := b.call(func() (err error) {
+		deleted, err = b.Cache.DeleteField(key, field)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return deleted, nil
+}
+
+// GetStatus gets the state of the circuit breaker of the cache, cache without a breaker is always closed
+func GetStatus(c Cache) Status {
+	if b, ok := c.(*Breaker); ok {
+		return b.Status()
+	}
+	return Status{State: literals.BreakerClosed}
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"errors"
+	"github.com/somprabhsharma/the-lazy-traveler/constants"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"github.com/somprabhsharma/the-lazy-traveler/models/redis"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
+	"time"
+)
+
+// ErrNotFound is returned when key or field is not present in the cache
+var ErrNotFound = errors.New("cache: key not found")
+
+// Cache stores values against keys along with hashes of fields against keys
+// values are the cached results which can expire, while hashes are the stores e.g. of flight schedules which do not expire
+type Cache interface {
+	// Ping checks if the cache is reachable
+	Ping() error
+
+	// Put puts value corresponding to key, zero ttl means value does not expire
+	Put(key, value string, ttl time.Duration) error
+
+	// Get gets value for given key
+	Get(key string) (string, error)
+
+	// Delete deletes value or hash stored at key
+	Delete(key string) error
+
+	// PutIfAbsent puts value corresponding to key only if key is not present, returns false if key was present
+	// it is used as a lock shared by all the users of the cache, where value is the token of the owner of the lock
+	PutIfAbsent(key, value string, ttl time.Duration) (bool, error)
+
+	// DeleteIfValue deletes value stored at key only if it is same as given value, returns false if it was not deleted
+	// it is used to release a lock only by its owner
+	DeleteIfValue(key, value string) (bool, error)
+
+	// PutField puts value corresponding to field of the hash stored at key
+	// every change of the fields of a hash increments version of the hash atomically with the change, so that readers of the version
+	// never see a changed hash under its old version
+	PutField(key, field, value string) error
+
+	// PutFieldIfPresent puts value corresponding to field of the hash stored at key only if field is present, returns false if it was not
+	// presence is checked and value is put atomically, so that a field deleted meanwhile is not put again
+	PutFieldIfPresent(key, field, value string) (bool, error)
+
+	// GetVersion gets version of the hash stored at key, which is zero until any field of the hash is changed
+	GetVersion(key string) (int64, error)
+
+	// GetField gets value of the field of the hash stored at key
+	GetField(key, field string) (string, error)
+
+	// GetAllFields gets all the fields and their values of the hash stored at key
+	GetAllFields(key string) (map[string]string, error)
+
+	// DeleteField deletes the field of the hash stored at key, returns false if field was not present
+	// version of the hash is incremented only if the field is deleted
+	DeleteField(key, field string) (bool, error)
+
+	// Close closes connections to the cache, cache must not be used after it is closed
+	Close() error
+}
+
+// New creates the cache of the backend configured in the environment i.e. redis, in memory lru or no cache at all
+// error is returned if the backend is unknown or its configuration is invalid
+func New() (Cache, error) {
+	switch constants.Env.CacheBackend {
+	case literals.MemoryCache:
+		return NewMemory(constants.Env.CacheMaxEntries), nil
+	case literals.NoCache:
+		return NewNoop(), nil
+	case literals.RedisCache:
+		redisClient, err := redis.NewClient()
+		if err != nil {
+			return nil, err
+		}
+		// redis is skipped while it is unhealthy and every call of redis has a deadline, so that requests do not wait for it
+		return NewBreaker(redisClient, redisClient.Ping, constants.Env.CacheBreakerFailures, constants.Env.CacheBreakerProbeInterval, constants.Env.CacheCallTimeout), nil
+	}
+
+	err := errors.New("invalid cache config: CACHE_BACKEND must be redis, memory or none")
+	logger.Err("Cache", "Error while configuring cache", err, constants.Env.CacheBackend)
+	return nil, err
+}
+
+// IsNotFound tells if the error is returned because key or field is not present in the cache
+func IsNotFound(err error) bool {
+	return err == ErrNotFound || redis.IsNotFound(err)
+}
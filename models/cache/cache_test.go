@@ -0,0 +1,267 @@
+package cache
+
+import (
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "The Lazy Traveler Suite")
+}
+
+var _ = Describe("models", func() {
+	Context("##memory", func() {
+		It("should save, return and delete value in memory cache", func() {
+			c := NewMemory(10)
+			_ = c.Put("key-123", "value", time.Minute)
+			val, err := c.Get("key-123")
+			Expect(err).Should(BeNil())
+			Expect(val).To(Equal("value"))
+
+			_ = c.Delete("key-123")
+			_, err = c.Get("key-123")
+			Expect(IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should expire value once its ttl has passed", func() {
+			c := NewMemory(10).(*memory)
+			now := time.Unix(1000, 0)
+			c.now = func() time.Time { return now }
+
+			_ = c.Put("key-123", "value", time.Minute)
+			_ = c.Put("key-456", "value", 0)
+			now = now.Add(time.Minute)
+			_, err := c.Get("key-123")
+			Expect(IsNotFound(err)).To(BeTrue())
+			val, _ := c.Get("key-456")
+			Expect(val).To(Equal("value"))
+			Expect(c.order.Len()).To(Equal(1))
+		})
+
+		It("should evict least recently used value once there are more than max entries values", func() {
+			c := NewMemory(2)
+			_ = c.Put("key-1", "1", time.Minute)
+			_ = c.Put("key-2", "2", time.Minute)
+			_, _ = c.Get("key-1")
+			_ = c.Put("key-3", "3", time.Minute)
+
+			_, err := c.Get("key-2")
+			Expect(IsNotFound(err)).To(BeTrue())
+			val, _ := c.Get("key-1")
+			Expect(val).To(Equal("1"))
+			val, _ = c.Get("key-3")
+			Expect(val).To(Equal("3"))
+		})
+
+		It("should save, return and delete field of a hash which is never evicted", func() {
+			c := NewMemory(1)
+			_ = c.PutField("hash-123", "field", "value")
+			_ = c.Put("key-1", "1", time.Minute)
+			_ = c.Put("key-2", "2", time.Minute)
+
+			val, _ := c.GetField("hash-123", "field")
+			Expect(val).To(Equal("value"))
+			values, _ := c.GetAllFields("hash-123")
+			Expect(values).To(HaveKeyWithValue("field", "value"))
+			deleted, _ := c.DeleteField("hash-123", "field")
+			Expect(deleted).To(BeTrue())
+			_, err := c.GetField("hash-123", "field")
+			Expect(IsNotFound(err)).To(BeTrue())
+			deleted, _ = c.DeleteField("hash-123", "field")
+			Expect(deleted).To(BeFalse())
+		})
+
+		It("should put field of a hash only if it is present", func() {
+			c := NewMemory(1)
+			put, _ := c.PutFieldIfPresent("hash-123", "field", "value")
+			Expect(put).To(BeFalse())
+			_, err := c.GetField("hash-123", "field")
+			Expect(IsNotFound(err)).To(BeTrue())
+
+			_ = c.PutField("hash-123", "field", "value")
+			put, _ = c.PutFieldIfPresent("hash-123", "field", "new-value")
+			Expect(put).To(BeTrue())
+			val, _ := c.GetField("hash-123", "field")
+			Expect(val).To(Equal("new-value"))
+		})
+
+		It("should increment version of a hash along with every change of its fields", func() {
+			c := NewMemory(1)
+			version, _ := c.GetVersion("hash-123")
+			Expect(version).To(Equal(int64(0)))
+
+			put, _ := c.PutFieldIfPresent("hash-123", "field", "value")
+			Expect(put).To(BeFalse())
+			deleted, _ := c.DeleteField("hash-123", "field")
+			Expect(deleted).To(BeFalse())
+			version, _ = c.GetVersion("hash-123")
+			Expect(version).To(Equal(int64(0)))
+
+			_ = c.PutField("hash-123", "field", "value")
+			_, _ = c.PutFieldIfPresent("hash-123", "field", "new-value")
+			_ = c.Put("key-1", "1", time.Minute)
+			_ = c.Put("key-2", "2", time.Minute)
+			_, _ = c.DeleteField("hash-123", "field")
+			version, _ = c.GetVersion("hash-123")
+			Expect(version).To(Equal(int64(3)))
+		})
+	})
+
+	Context("##breaker", func() {
+		var failing *failingCache
+		BeforeEach(func() {
+			failing = &failingCache{Cache: NewMemory(0)}
+		})
+		healthy := func() error {
+			if failing.isFailing() {
+				return errors.New("cache is down")
+			}
+			return nil
+		}
+
+		It("should skip the cache once calls fail consecutively and close once the probe succeeds", func() {
+			b := NewBreaker(failing, healthy, 2, 10*time.Millisecond, 0)
+			Expect(b.Status().State).To(Equal(literals.BreakerClosed))
+
+			// missing key is not a failure
+			_, err := b.Get("key-123")
+			Expect(IsNotFound(err)).To(BeTrue())
+			Expect(b.Status().ConsecutiveFailures).To(Equal(0))
+
+			failing.setFailing(true)
+			_, _ = b.Get("key-123")
+			Expect(b.Status().State).To(Equal(literals.BreakerClosed))
+			_ = b.Put("key-123", "value", time.Minute)
+			Expect(b.Status().State).To(Equal(literals.BreakerOpen))
+			Expect(b.Status().LastError).To(Equal("cache is down"))
+
+			calls := failing.calls
+			_, err = b.Get("key-123")
+			Expect(err).To(Equal(ErrUnavailable))
+			Expect(failing.calls).To(Equal(calls))
+
+			failing.setFailing(false)
+			Eventually(func() string { return b.Status().State }).Should(Equal(literals.BreakerClosed))
+			Expect(b.Put("key-123", "value", time.Minute)).Should(BeNil())
+			val, _ := b.Get("key-123")
+			Expect(val).To(Equal("value"))
+		})
+
+		It("should fail calls which do not complete within call timeout and open once they fail consecutively", func() {
+			slow := &slowCache{Cache: NewMemory(0)}
+			b := NewBreaker(slow, healthy, 2, time.Hour, 50*time.Millisecond)
+			Expect(b.Put("key-123", "value", time.Minute)).Should(BeNil())
+			val, _ := b.Get("key-123")
+			Expect(val).To(Equal("value"))
+
+			slow.delay = time.Second
+			start := time.Now()
+			val, err := b.Get("key-123")
+			Expect(err).To(Equal(ErrTimeout))
+			Expect(val).To(BeEmpty())
+			Expect(b.Status().State).To(Equal(literals.BreakerClosed))
+			_, err = b.Get("key-123")
+			Expect(err).To(Equal(ErrTimeout))
+			Expect(b.Status().State).To(Equal(literals.BreakerOpen))
+			_, err = b.Get("key-123")
+			Expect(err).To(Equal(ErrUnavailable))
+			Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond))
+		})
+
+		It("should start open if the cache is not reachable", func() {
+			failing.setFailing(true)
+			b := NewBreaker(failing, healthy, 2, time.Hour, 0)
+			Expect(b.Status().State).To(Equal(literals.BreakerOpen))
+			Expect(GetStatus(b).State).To(Equal(literals.BreakerOpen))
+			Expect(GetStatus(NewNoop()).State).To(Equal(literals.BreakerClosed))
+			failing.setFailing(false)
+		})
+
+		It("should start open without waiting for a cache which does not respond within call timeout", func() {
+			slowPing := func() error {
+				time.Sleep(time.Second)
+				return nil
+			}
+			start := time.Now()
+			b := NewBreaker(failing, slowPing, 2, time.Hour, 50*time.Millisecond)
+			Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond))
+			Expect(b.Status().State).To(Equal(literals.BreakerOpen))
+			Expect(b.Status().LastError).To(Equal(ErrTimeout.Error()))
+		})
+	})
+
+	Context("##noop", func() {
+		It("should not store any value and fail to put any field", func() {
+			c := NewNoop()
+			Expect(c.Put("key-123", "value", time.Minute)).Should(BeNil())
+			_, err := c.Get("key-123")
+			Expect(IsNotFound(err)).To(BeTrue())
+
+			Expect(c.PutField("hash-123", "field", "value")).To(Equal(ErrUnavailable))
+			version, _ := c.GetVersion("hash-123")
+			Expect(version).To(Equal(int64(0)))
+			_, err = c.GetField("hash-123", "field")
+			Expect(IsNotFound(err)).To(BeTrue())
+			values, _ := c.GetAllFields("hash-123")
+			Expect(values).To(BeEmpty())
+		})
+	})
+})
+
+// failingCache is a cache whose calls fail while it is failing
+type failingCache struct {
+	Cache
+	mu      sync.Mutex
+	failing bool
+	calls   int
+}
+
+// setFailing sets whether calls of the cache fail
+func (f *failingCache) setFailing(failing bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failing = failing
+}
+
+// isFailing tells if calls of the cache fail
+func (f *failingCache) isFailing() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.failing
+}
+
+// Put puts value unless the cache is failing
+func (f *failingCache) Put(key, value string, ttl time.Duration) error {
+	f.calls++
+	if f.isFailing() {
+		return errors.New("cache is down")
+	}
+	return f.Cache.Put(key, value, ttl)
+}
+
+// Get gets value unless the cache is failing
+func (f *failingCache) Get(key string) (string, error) {
+	f.calls++
+	if f.isFailing() {
+		return "", errors.New("cache is down")
+	}
+	return f.Cache.Get(key)
+}
+
+// slowCache is a cache whose gets take delay to complete
+type slowCache struct {
+	Cache
+	delay time.Duration
+}
+
+// Get gets value after the delay
+func (s *slowCache) Get(key string) (string, error) {
+	time.Sleep(s.delay)
+	return s.Cache.Get(key)
+}
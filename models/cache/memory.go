@@ -0,0 +1,228 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// memory is an in process cache which keeps up to max entries values, least recently used value is evicted to make room for a new one
+// hashes are the stores e.g. of flight schedules, hence they are not counted in max entries and are never evicted
+type memory struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element // element of every value in the order
+	order      *list.List               // values from most recently used to least recently used
+	hashes     map[string]map[string]string
+	versions   map[string]int64 // version of every hash which has changed
+	now        func() time.Time
+}
+
+// memoryEntry is a value stored in the memory cache
+type memoryEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time // zero time means value does not expire
+}
+
+// NewMemory creates an in process cache which keeps up to max entries values, zero max entries means there is no limit
+func NewMemory(maxEntries int) Cache {
+	return &memory{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		hashes:     make(map[string]map[string]string),
+		versions:   make(map[string]int64),
+		now:        time.Now,
+	}
+}
+
+// Ping always succeeds, as memory of the process is always reachable
+func (m *memory) Ping() error {
+	return nil
+}
+
+// Close has nothing to close, values stay in memory of the process
+func (m *memory) Close() error {
+	return nil
+}
+
+// Put puts value corresponding to key, least recently used values are evicted if there are more than max entries values
+func (m *memory) Put(key, value string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.put(key, value, ttl)
+	return nil
+}
+
+// put puts value corresponding to key, caller must hold the lock of the cache
+func (m *memory) put(key, value string, ttl time.Duration) {
+	entry := &memoryEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expiresAt = m.now().Add(ttl)
+	}
+
+	if element, ok := m.entries[key]; ok {
+		element.Value = entry
+		m.order.MoveToFront(element)
+	} else {
+		m.entries[key] = m.order.PushFront(entry)
+	}
+
+	for m.maxEntries > 0 && m.order.Len() > m.maxEntries {
+		m.removeElement(m.order.Back())
+	}
+}
+
+// Get gets value for given key, expired values are removed when they are read
+func (m *memory) Get(key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.getElement(key)
+	if !ok {
+		return "", ErrNotFound
+	}
+	m.order.MoveToFront(element)
+	return element.Value.(*memoryEntry).value, nil
+}
+
+// PutIfAbsent puts value corresponding to key only if key is not present or its value has expired
+func (m *memory) PutIfAbsent(key, value string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.getElement(key); ok {
+		return false, nil
+	}
+	m.put(key, value, ttl)
+	return true, nil
+}
+
+// DeleteIfValue deletes value stored at key only if it is same as given value
+func (m *memory) DeleteIfValue(key, value string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.getElement(key)
+	if !ok || element.Value.(*memoryEntry).value != value {
+		return false, nil
+	}
+	m.removeElement(element)
+	return true, nil
+}
+
+// getElement gets element of the value stored at key, expired value is removed and is not returned
+func (m *memory) getElement(key string) (*list.Element, bool) {
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && !m.now().Before(entry.expiresAt) {
+		m.removeElement(element)
+		return nil, false
+	}
+	return element, true
+}
+
+// Delete deletes value or hash stored at key
+func (m *memory) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		m.removeElement(element)
+	}
+	delete(m.hashes, key)
+	return nil
+}
+
+// removeElement removes the value of the element from the cache
+func (m *memory) removeElement(element *list.Element) {
+	m.order.Remove(element)
+	delete(m.entries, element.Value.(*memoryEntry).key)
+}
+
+// PutField puts value corresponding to field of the hash stored at key
+func (m *memory) PutField(key, field, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hash, ok := m.hashes[key]
+	if !ok {
+		hash = make(map[string]string)
+		m.hashes[key] = hash
+	}
+	hash[field] = value
+	m.versions[key]++
+	return nil
+}
+
+// PutFieldIfPresent puts value corresponding to field of the hash stored at key only if field is present
+func (m *memory) PutFieldIfPresent(key, field, value string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hash := m.hashes[key]
+	if _, ok := hash[field]; !ok {
+		return false, nil
+	}
+	hash[field] = value
+	m.versions[key]++
+	return true, nil
+}
+
+// GetVersion gets version of the hash stored at key, which is zero until any field of the hash is changed
+func (m *memory) GetVersion(key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.versions[key], nil
+}
+
+// GetField gets value of the field of the hash stored at key
+func (m *memory) GetField(key, field string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	value, ok := m.hashes[key][field]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// GetAllFields gets all the fields and their values of the hash stored at key
+func (m *memory) GetAllFields(key string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// copy the hash, so that it is not modified while caller reads it
+	values := make(map[string]string, len(m.hashes[key]))
+	for field, value := range m.hashes[key] {
+		values[field] = value
+	}
+	return values, nil
+}
+
+// DeleteField deletes the field of the hash stored at key, returns false if field was not present
+func (m *memory) DeleteField(key, field string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hash, ok := m.hashes[key]
+	if !ok {
+		return false, nil
+	}
+	if _, ok = hash[field]; !ok {
+		return false, nil
+	}
+	delete(hash, field)
+	if len(hash) == 0 {
+		delete(m.hashes, key)
+	}
+	m.versions[key]++
+	return true, nil
+}
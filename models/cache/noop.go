@@ -0,0 +1,77 @@
+package cache
+
+import "time"
+
+// noop is a cache which does not store anything, every key and field is always missing
+// values are only cached results which can be computed again, while hashes are stores hence putting a field fails instead of losing it
+type noop struct{}
+
+// NewNoop creates a cache which does not store anything
+func NewNoop() Cache {
+	return noop{}
+}
+
+// Ping always succeeds, as there is nothing to reach
+func (noop) Ping() error {
+	return nil
+}
+
+// Close has nothing to close
+func (noop) Close() error {
+	return nil
+}
+
+// Put does not store the value
+func (noop) Put(key, value string, ttl time.Duration) error {
+	return nil
+}
+
+// Get never finds the key
+func (noop) Get(key string) (string, error) {
+	return "", ErrNotFound
+}
+
+// Delete has nothing to delete
+func (noop) Delete(key string) error {
+	return nil
+}
+
+// PutIfAbsent does not store the value, key is always absent hence it always succeeds
+func (noop) PutIfAbsent(key, value string, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+// DeleteIfValue has nothing to delete
+func (noop) DeleteIfValue(key, value string) (bool, error) {
+	return false, nil
+}
+
+// PutField fails, as the value would be lost
+func (noop) PutField(key, field, value string) error {
+	return ErrUnavailable
+}
+
+// PutFieldIfPresent does not store the value, field is never present
+func (noop) PutFieldIfPresent(key, field, value string) (bool, error) {
+	return false, nil
+}
+
+// GetVersion always gets zero, as no field is ever changed
+func (noop) GetVersion(key string) (int64, error) {
+	return 0, nil
+}
+
+// GetField never finds the field
+func (noop) GetField(key, field string) (string, error) {
+	return "", ErrNotFound
+}
+
+// GetAllFields always gets an empty hash
+func (noop) GetAllFields(key string) (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+// DeleteField has nothing to delete
+func (noop) DeleteField(key, field string) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,186 @@
+package catalog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/errorconsts"
+	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// aliasSeparator separates aliases of a city in the csv catalog file
+	aliasSeparator = "|"
+)
+
+// City is a city or an airport in the catalog
+// a city that is metro area of other cities groups their airports under its code
+type City struct {
+	Code      string   `json:"code"`
+	Name      string   `json:"name"`
+	MetroArea string   `json:"metro_area,omitempty"`
+	Aliases   []string `json:"aliases,omitempty"`
+}
+
+// Catalog is a reference catalog of cities and airports used to normalize city names given in the requests
+type Catalog struct {
+	// Strict rejects the cities which are not in the catalog, otherwise they are used as they are
+	Strict bool
+
+	cities     map[string]*City    // cities by their code
+	names      map[string]string   // codes of the cities by their normalized code, name & aliases
+	metroAreas map[string][]string // codes of the airports by code of their metro area
+}
+
+// New creates a catalog of given cities
+// codes, names & aliases are matched case insensitively, so they must be unique irrespective of their case
+func New(cities []*City) (*Catalog, error) {
+	c := &Catalog{
+		cities:     make(map[string]*City, len(cities)),
+		names:      make(map[string]string, len(cities)),
+		metroAreas: make(map[string][]string),
+	}
+
+	for _, city := range cities {
+		if city == nil || normalizeName(city.Code) == "" {
+			return nil, errors.New("city code is missing in the catalog")
+		}
+		code := normalizeName(city.Code)
+		if _, ok := c.cities[code]; ok {
+			return nil, errors.New("duplicate city code in the catalog: " + code)
+		}
+		c.cities[code] = city
+		c.names[code] = code
+	}
+
+	for _, city := range cities {
+		code := normalizeName(city.Code)
+		for _, name := range append([]string{city.Name}, city.Aliases...) {
+			key := normalizeName(name)
+			if key == "" {
+				continue
+			}
+			// codes take precedence over names, so that a city name can be same as code of another city
+			if existingCode, ok := c.names[key]; ok && existingCode != code {
+				if _, isCode := c.cities[key]; isCode {
+					continue
+				}
+				return nil, errors.New("duplicate city name in the catalog: " + name)
+			}
+			c.names[key] = code
+		}
+
+		if city.MetroArea == "" {
+			continue
+		}
+		metroArea := normalizeName(city.MetroArea)
+		if _, ok := c.cities[metroArea]; !ok || metroArea == code {
+			return nil, errors.New("invalid metro area of city " + code + " in the catalog: " + city.MetroArea)
+		}
+		c.metroAreas[metroArea] = append(c.metroAreas[metroArea], code)
+	}
+
+	for metroArea := range c.metroAreas {
+		if c.cities[metroArea].MetroArea != "" {
+			return nil, errors.New("metro area cannot be part of another metro area in the catalog: " + metroArea)
+		}
+	}
+	return c, nil
+}
+
+// Load loads the catalog from a csv or json file
+// csv file has code, name, metro_area & aliases columns with a header row, aliases are separated by |
+// json file has an array of cities
+func Load(path string) (*Catalog, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var cities []*City
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		cities, err = readCSV(file)
+	case ".json":
+		err = json.NewDecoder(file).Decode(&cities)
+	default:
+		err = errors.New("catalog file must be a csv or json file: " + path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return New(cities)
+}
+
+// readCSV reads cities from csv catalog file
+func readCSV(r io.Reader) ([]*City, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("header row is missing in the catalog file")
+	}
+
+	// skip the header row
+	cities := make([]*City, 0, len(records)-1)
+	for _, record := range records[1:] {
+		city := &City{
+			Code:      record[0],
+			Name:      record[1],
+			MetroArea: record[2],
+		}
+		if record[3] != "" {
+			city.Aliases = strings.Split(record[3], aliasSeparator)
+		}
+		cities = append(cities, city)
+	}
+	return cities, nil
+}
+
+// Normalize gets code of the city with given code, name or alias
+// cities which are not in the catalog are returned without surrounding spaces, unless the catalog is strict
+func (c *Catalog) Normalize(name string) (string, error) {
+	if code, ok := c.names[normalizeName(name)]; ok {
+		return code, nil
+	}
+	if c.Strict {
+		return "", errors.New(errorconsts.UnknownCity)
+	}
+	return strings.TrimSpace(name), nil
+}
+
+// NormalizeSchedule normalizes departure and arrival cities of the flight schedule
+func (c *Catalog) NormalizeSchedule(schedule *flightpath.FlightDetail) error {
+	for _, detail := range []*flightpath.ScheduleDetail{schedule.Departure, schedule.Arrival} {
+		if detail == nil {
+			continue
+		}
+		city, err := c.Normalize(detail.City)
+		if err != nil {
+			return err
+		}
+		detail.City = city
+	}
+	return nil
+}
+
+// Airports gets the cities which can be used to fly from or to the city with given code
+// i.e. the metro area itself along with all its airports, or just the city if it is not a metro area
+func (c *Catalog) Airports(code string) []string {
+	return append([]string{code}, c.metroAreas[code]...)
+}
+
+// normalizeName normalizes the name for case insensitive matching, ignoring extra spaces
+func normalizeName(name string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,119 @@
+package catalog
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/errorconsts"
+	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "The Lazy Traveler Suite")
+}
+
+var _ = Describe("models", func() {
+	Context("##catalog", func() {
+		It("should load bundled catalog and normalize codes, names & aliases case insensitively", func() {
+			c, err := Load("../../data/cities.csv")
+			Expect(err).Should(BeNil())
+
+			for _, name := range []string{"JFK", "jfk", " John F.  Kennedy International Airport ", "kennedy"} {
+				code, err := c.Normalize(name)
+				Expect(err).Should(BeNil())
+				Expect(code).To(Equal("JFK"))
+			}
+			code, err := c.Normalize("Bombay")
+			Expect(err).Should(BeNil())
+			Expect(code).To(Equal("BOM"))
+		})
+
+		It("should return all the airports of a metro area along with the metro area itself", func() {
+			c, _ := Load("../../data/cities.csv")
+			Expect(c.Airports("NYC")).To(Equal([]string{"NYC", "JFK", "LGA", "EWR"}))
+			Expect(c.Airports("JFK")).To(Equal([]string{"JFK"}))
+			Expect(c.Airports("UNKNOWN")).To(Equal([]string{"UNKNOWN"}))
+		})
+
+		It("should return unknown cities as they are unless catalog is strict", func() {
+			c, _ := Load("../../data/cities.csv")
+			code, err := c.Normalize(" Atlantis ")
+			Expect(err).Should(BeNil())
+			Expect(code).To(Equal("Atlantis"))
+
+			c.Strict = true
+			code, err = c.Normalize("Atlantis")
+			Expect(code).To(BeEmpty())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(Equal(errorconsts.UnknownCity))
+		})
+
+		It("should normalize departure & arrival cities of flight schedule", func() {
+			c, _ := Load("../../data/cities.csv")
+			schedule := &flightpath.FlightDetail{
+				Departure: &flightpath.ScheduleDetail{City: "heathrow"},
+				Arrival:   &flightpath.ScheduleDetail{City: "New Delhi"},
+			}
+			Expect(c.NormalizeSchedule(schedule)).Should(BeNil())
+			Expect(schedule.Departure.City).To(Equal("LHR"))
+			Expect(schedule.Arrival.City).To(Equal("DEL"))
+		})
+
+		It("should load catalog from json file", func() {
+			dir, _ := ioutil.TempDir("", "catalog")
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "cities.json")
+			_ = ioutil.WriteFile(path, []byte(`[
+				{"code": "SEA", "name": "Seattle"},
+				{"code": "sea-tac", "name": "Seattle-Tacoma International Airport", "metro_area": "SEA", "aliases": ["SeaTac"]}
+			]`), 0600)
+
+			c, err := Load(path)
+			Expect(err).Should(BeNil())
+			code, _ := c.Normalize("seatac")
+			Expect(code).To(Equal("SEA-TAC"))
+			Expect(c.Airports("SEA")).To(Equal([]string{"SEA", "SEA-TAC"}))
+		})
+
+		It("should prefer code of a city over same name of another city", func() {
+			c, err := New([]*City{
+				{Code: "LA", Name: "Los Angeles"},
+				{Code: "LAX", Name: "Los Angeles International Airport", Aliases: []string{"LA"}},
+			})
+			Expect(err).Should(BeNil())
+			code, _ := c.Normalize("la")
+			Expect(code).To(Equal("LA"))
+		})
+
+		It("should throw error if a name belongs to more than one city", func() {
+			_, err := New([]*City{
+				{Code: "PDX", Name: "Portland"},
+				{Code: "PWM", Name: "Portland"},
+			})
+			Expect(err).ShouldNot(BeNil())
+		})
+
+		It("should throw error if metro area is not in the catalog or is part of another metro area", func() {
+			_, err := New([]*City{
+				{Code: "JFK", Name: "John F. Kennedy International Airport", MetroArea: "NYC"},
+			})
+			Expect(err).ShouldNot(BeNil())
+
+			_, err = New([]*City{
+				{Code: "NYC", Name: "New York City", MetroArea: "USA"},
+				{Code: "USA", Name: "United States"},
+				{Code: "JFK", Name: "John F. Kennedy International Airport", MetroArea: "NYC"},
+			})
+			Expect(err).ShouldNot(BeNil())
+		})
+
+		It("should throw error if catalog file is neither csv nor json", func() {
+			_, err := Load("../../README.md")
+			Expect(err).ShouldNot(BeNil())
+		})
+	})
+})
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
+	"sync"
+)
+
+// call is a computation which is running or has completed
+type call struct {
+	done    chan struct{} // closed once the computation completes
+	value   string
+	err     error
+	waiters int                // number of callers waiting for the computation
+	cancel  context.CancelFunc // cancels context of the computation
+}
+
+// callGroup coalesces computations by their key, so that only one computation of a key runs at a time in the process
+// callers asking for a key while its computation is running wait for it and get its result instead of computing it again
+type callGroup struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// newCallGroup creates a new call group
+func newCallGroup() *callGroup {
+	return &callGroup{calls: make(map[string]*call)}
+}
+
+// do runs the computation of the key unless it is already running, in which case it waits for the running computation
+// shared tells if the result is of a computation which was started by another caller
+// computation does not run in the context of the caller which started it, as other callers wait for it as well,
+// caller stops waiting once its context is done and the computation is canceled once no caller is waiting for it
+func (g *callGroup) do(ctx context.Context, key string, compute func(ctx context.Context) (string, error)) (value string, shared bool, err error) {
+	g.mu.Lock()
+	c, shared := g.calls[key]
+	if !shared {
+		computeCtx, cancel := context.WithCancel(context.Background())
+		c = &call{
+			done:   make(chan struct{}),
+			err:    errors.New("computation of the key did not complete"),
+			cancel: cancel,
+		}
+		g.calls[key] = c
+		go g.run(computeCtx, key, c, compute)
+	}
+	c.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.value, shared, c.err
+	case <-ctx.Done():
+		g.leave(key, c)
+		return "", shared, ctx.Err()
+	}
+}
+
+// run runs the computation of the call and releases the callers waiting for it
+func (g *callGroup) run(ctx context.Context, key string, c *call, compute func(ctx context.Context) (string, error)) {
+	// the call is removed even if computation panics, so that waiting callers are released and next caller computes again
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Err(literals.LazyJack, "computation of the key panicked: "+key, c.err, r)
+		}
+		g.mu.Lock()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+		c.cancel()
+		close(c.done)
+	}()
+
+	c.value, c.err = compute(ctx)
+}
+
+// leave stops waiting for the call, the computation is canceled if no other caller is waiting for it
+// canceled call is removed at once, so that next caller computes again instead of waiting for the canceled computation
+func (g *callGroup) leave(key string, c *call) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	c.waiters--
+	if c.waiters == 0 {
+		c.cancel()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+	}
+}
@@ -1,18 +1,57 @@
 package models
 
-import "github.com/somprabhsharma/the-lazy-traveler/models/redis"
+import (
+	"github.com/somprabhsharma/the-lazy-traveler/constants"
+	"github.com/somprabhsharma/the-lazy-traveler/models/cache"
+	"github.com/somprabhsharma/the-lazy-traveler/models/catalog"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
+)
 
 // Dao dao struct
 type Dao struct {
-	Cache           *redis.Client
+	Cache           cache.Cache
+	CityCatalog     *catalog.Catalog
 	FlightPathModel *flightPathModel
+	ScheduleModel   *scheduleModel
 }
 
-// NewDao creates instance of Dao
-func NewDao() *Dao {
-	redisClient := redis.NewClient()
+// NewDao creates instance of Dao with the cache backend configured in the environment
+// results and flight schedules are both kept in the configured backend, redis is dialed only if the backend is redis
+// error is returned if the cache is not configured properly
+func NewDao() (*Dao, error) {
+	c, err := cache.New()
+	if err != nil {
+		return nil, err
+	}
+	return NewDaoWithCache(c), nil
+}
+
+// NewDaoWithCache creates instance of Dao which stores results and flight schedules in given cache
+func NewDaoWithCache(c cache.Cache) *Dao {
 	return &Dao{
-		Cache:           redisClient,
-		FlightPathModel: newFlightPathModel(redisClient),
+		Cache:           c,
+		CityCatalog:     newCityCatalog(),
+		FlightPathModel: newFlightPathModel(c),
+		ScheduleModel:   newScheduleModel(c),
+	}
+}
+
+// Close waits for the results being refreshed in background and then closes the cache of the dao
+// dao must not be used after it is closed
+func (d *Dao) Close() error {
+	d.FlightPathModel.Close()
+	return d.Cache.Close()
+}
+
+// newCityCatalog loads the city catalog from the configured file
+// an empty catalog is used if the catalog cannot be loaded i.e. cities are used as they are
+func newCityCatalog() *catalog.Catalog {
+	cityCatalog, err := catalog.Load(constants.Env.CityCatalogFile)
+	if err != nil {
+		logger.Warn("Catalog", "Error while loading city catalog, cities will not be normalized", err, constants.Env.CityCatalogFile)
+		cityCatalog, _ = catalog.New(nil)
+		return cityCatalog
 	}
+	cityCatalog.Strict = constants.Env.CityCatalogStrict
+	return cityCatalog
 }
@@ -1,35 +1,74 @@
 package models
 
 import (
-	"encoding/base64"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"github.com/somprabhsharma/the-lazy-traveler/constants"
 	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
 	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
-	"github.com/somprabhsharma/the-lazy-traveler/models/redis"
+	"github.com/somprabhsharma/the-lazy-traveler/models/cache"
 	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
 const (
-	flightPathSuffix = "-flight-path"
-	flightPathTTL    = 24 * time.Hour
+	flightPathSuffix  = "-flight-path"
+	itinerariesSuffix = "-itineraries"
+	lockSuffix        = "-lock"
+	flightPathTTL     = 24 * time.Hour
+	lockPollInterval  = 50 * time.Millisecond // interval at which result is checked while another instance computes it
+
+	// cacheKeyPrefix is prefix of the keys of all the cached results
+	cacheKeyPrefix = "the-lazy-traveler-v"
+	// cacheKeyVersion is version of the cache keys, it must be bumped whenever same request can find different itineraries
+	// e.g. when the ranking of itineraries changes or when format of cached results changes,
+	// so that results cached by older versions are never returned
+	cacheKeyVersion = 3
 )
 
 type flightPathModel struct {
-	Cache *redis.Client
+	Cache    cache.Cache
+	ttl      time.Duration // time for which a cached result is fresh
+	staleTTL time.Duration // time after expiry for which a cached result is served while it is refreshed, zero means never
+	lockTTL  time.Duration // maximum time for which computation of a result is locked across the instances sharing the cache
+	calls    *callGroup
+	now      func() time.Time
+
+	// refreshes are the stale results being refreshed in background, model is closed once they complete
+	mu        sync.Mutex
+	refreshes sync.WaitGroup
+	closed    bool
+}
+
+// cachedResult is a result saved in cache along with the time at which it expires
+// result is kept in cache for stale ttl after it expires, so that it can be served while it is refreshed
+type cachedResult struct {
+	Value     json.RawMessage `json:"value"`
+	ExpiresAt int64           `json:"expires_at"` // unix time in nanoseconds
 }
 
-func newFlightPathModel(redis *redis.Client) *flightPathModel {
+func newFlightPathModel(c cache.Cache) *flightPathModel {
 	return &flightPathModel{
-		Cache: redis,
+		Cache:    c,
+		ttl:      flightPathTTL,
+		staleTTL: constants.Env.CacheStaleTTL,
+		lockTTL:  constants.Env.CacheLockTTL,
+		calls:    newCallGroup(),
+		now:      time.Now,
 	}
 }
 
 // Put puts shortest path result in cache
 func (t *flightPathModel) Put(shortestPath []flightpath.ScheduleDetail, data flightpath.LazyJackRequest) error {
 	// generate key from input data
-	key, err := generateCacheKey(data)
+	key, err := generateCacheKey(data, flightPathSuffix)
 	if err != nil {
 		return err
 	}
@@ -42,45 +81,344 @@ func (t *flightPathModel) Put(shortestPath []flightpath.ScheduleDetail, data fli
 	}
 
 	// save it in cache
-	return t.Cache.Put(key, string(shortestPathBytes), flightPathTTL)
+	return t.put(key, string(shortestPathBytes))
 }
 
-// Get gets shortest path result from cache
+// Get gets shortest path result from cache, expired result is not returned
 func (t *flightPathModel) Get(data flightpath.LazyJackRequest) ([]flightpath.ScheduleDetail, error) {
-	// generate key from input data
-	key, err := generateCacheKey(data)
+	var shortestPath []flightpath.ScheduleDetail
+	err := t.getResult(data, flightPathSuffix, &shortestPath)
 	if err != nil {
 		return nil, err
 	}
+	return shortestPath, nil
+}
+
+// GetOrCompute gets shortest path result from cache, it is computed and saved in cache if it is not there
+// error of the context is returned if it is done before the result is found
+func (t *flightPathModel) GetOrCompute(ctx context.Context, data flightpath.LazyJackRequest, compute func(ctx context.Context) ([]flightpath.ScheduleDetail, error)) ([]flightpath.ScheduleDetail, error) {
+	var shortestPath []flightpath.ScheduleDetail
+	err := t.getOrComputeResult(ctx, data, flightPathSuffix, &shortestPath, func(ctx context.Context) (interface{}, error) {
+		return compute(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return shortestPath, nil
+}
+
+// PutItineraries puts k shortest itineraries result in cache
+func (t *flightPathModel) PutItineraries(itineraries []*flightpath.Itinerary, data flightpath.LazyJackRequest) error {
+	// generate key from input data
+	key, err := generateCacheKey(data, itinerariesSuffix)
+	if err != nil {
+		return err
+	}
+
+	// stringify the itineraries result
+	itinerariesBytes, err := json.Marshal(itineraries)
+	if err != nil {
+		logger.Warn(literals.LazyJack, "error while saving itineraries data in cache for key: "+key, err, nil)
+		return nil
+	}
 
 	// save it in cache
-	value, err := t.Cache.Get(key)
+	return t.put(key, string(itinerariesBytes))
+}
+
+// GetItineraries gets k shortest itineraries result from cache, expired result is not returned
+func (t *flightPathModel) GetItineraries(data flightpath.LazyJackRequest) ([]*flightpath.Itinerary, error) {
+	var itineraries []*flightpath.Itinerary
+	err := t.getResult(data, itinerariesSuffix, &itineraries)
 	if err != nil {
-		logger.Warn(literals.LazyJack, "error while getting shortest path data from cache for key: "+key, err, nil)
 		return nil, err
 	}
+	return itineraries, nil
+}
 
-	// unmarshal data obtained from cache
-	var shortestPath []flightpath.ScheduleDetail
-	err = json.Unmarshal([]byte(value), &shortestPath)
+// GetOrComputeItineraries gets k shortest itineraries result from cache, they are computed and saved in cache if they are not there
+// error of the context is returned if it is done before the result is found
+func (t *flightPathModel) GetOrComputeItineraries(ctx context.Context, data flightpath.LazyJackRequest, compute func(ctx context.Context) ([]*flightpath.Itinerary, error)) ([]*flightpath.Itinerary, error) {
+	var itineraries []*flightpath.Itinerary
+	err := t.getOrComputeResult(ctx, data, itinerariesSuffix, &itineraries, func(ctx context.Context) (interface{}, error) {
+		return compute(ctx)
+	})
 	if err != nil {
-		logger.Warn(literals.LazyJack, "error while un marshalling shortest path data obtained from cache for key: "+key, err, nil)
 		return nil, err
 	}
+	return itineraries, nil
+}
 
-	return shortestPath, nil
+// getResult gets fresh result of the input data from cache into result
+func (t *flightPathModel) getResult(data flightpath.LazyJackRequest, suffix string, result interface{}) error {
+	// generate key from input data
+	key, err := generateCacheKey(data, suffix)
+	if err != nil {
+		return err
+	}
+
+	value, fresh, err := t.get(key)
+	if err != nil {
+		logger.Warn(literals.LazyJack, "error while getting data from cache for key: "+key, err, nil)
+		return err
+	}
+	if !fresh {
+		return cache.ErrNotFound
+	}
+
+	// unmarshal data obtained from cache
+	err = json.Unmarshal([]byte(value), result)
+	if err != nil {
+		logger.Warn(literals.LazyJack, "error while un marshalling data obtained from cache for key: "+key, err, nil)
+		return err
+	}
+	return nil
+}
+
+// getOrComputeResult gets result of the input data from cache into result, it is computed and saved in cache if it is not there
+// every caller gets its own copy of the result, as result is always unmarshalled from its json
+func (t *flightPathModel) getOrComputeResult(ctx context.Context, data flightpath.LazyJackRequest, suffix string, result interface{}, compute func(ctx context.Context) (interface{}, error)) error {
+	computeJSON := func(ctx context.Context) (string, error) {
+		value, err := compute(ctx)
+		if err != nil {
+			return "", err
+		}
+		valueBytes, err := json.Marshal(value)
+		return string(valueBytes), err
+	}
+
+	var value string
+	key, err := generateCacheKey(data, suffix)
+	if err == nil {
+		value, err = t.getOrComputeValue(ctx, key, computeJSON)
+	} else {
+		// result cannot be cached without a key, hence it is just computed
+		value, err = computeJSON(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal([]byte(value), result)
+	if err != nil {
+		logger.Warn(literals.LazyJack, "error while un marshalling data for key: "+key, err, nil)
+		return err
+	}
+	return nil
+}
+
+// getOrComputeValue gets value of the key from cache, value is computed if it is not in cache
+// expired value which is still in cache is returned while it is refreshed in background if stale ttl is set, otherwise it is computed
+// only one computation of a key runs at a time in the process, callers asking for the key meanwhile get value of that computation
+func (t *flightPathModel) getOrComputeValue(ctx context.Context, key string, compute func(ctx context.Context) (string, error)) (string, error) {
+	computeValue := func(ctx context.Context) (string, error) {
+		return t.computeValue(ctx, key, compute)
+	}
+
+	// cache is not called for a request which has already timed out or has been closed
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	value, fresh, err := t.get(key)
+	if err == nil && fresh {
+		logger.Info(literals.LazyJack, "returning result from cache for key: "+key, nil)
+		return value, nil
+	}
+	if err == nil && t.staleTTL > 0 {
+		logger.Info(literals.LazyJack, "returning stale result from cache while refreshing it for key: "+key, nil)
+		t.refresh(key, computeValue)
+		return value, nil
+	}
+
+	value, shared, err := t.calls.do(ctx, key, computeValue)
+	if shared {
+		logger.Info(literals.LazyJack, "returning result computed for a concurrent request with key: "+key, nil)
+	}
+	return value, err
+}
+
+// refresh computes value of the key again in background, refresh is not started once the model is closed
+// refresh is not tied to the request which found the stale result, so that it completes even if the request does not wait for it
+func (t *flightPathModel) refresh(key string, computeValue func(ctx context.Context) (string, error)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+
+	t.refreshes.Add(1)
+	go func() {
+		defer t.refreshes.Done()
+		_, _, err := t.calls.do(context.Background(), key, computeValue)
+		if err != nil {
+			logger.Warn(literals.LazyJack, "error while refreshing stale result for key: "+key, err, nil)
+		}
+	}()
+}
+
+// Close stops refreshing stale results and waits for the running refreshes, so that cache is not closed while they write to it
+func (t *flightPathModel) Close() {
+	t.mu.Lock()
+	t.closed = true
+	t.mu.Unlock()
+	t.refreshes.Wait()
+}
+
+// computeValue computes value of the key and saves it in cache, while holding the lock of the key in cache
+// so that only one instance sharing the cache computes the value, other instances wait for the value until the lock expires
+// value is computed without the lock if lock ttl is zero, if cache cannot be reached or if the lock expires without any value
+// waiting for the lock stops once the context is done, which fails the computation with error of the context
+func (t *flightPathModel) computeValue(ctx context.Context, key string, compute func(ctx context.Context) (string, error)) (string, error) {
+	lockKey := key + lockSuffix
+	token := newLockToken()
+	locked, err := false, error(nil)
+	if t.lockTTL > 0 {
+		locked, err = t.Cache.PutIfAbsent(lockKey, token, t.lockTTL)
+	}
+	for start := time.Now(); err == nil && !locked && time.Since(start) < t.lockTTL; {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+		if value, fresh, err := t.get(key); err == nil && fresh {
+			logger.Info(literals.LazyJack, "returning result computed by another instance for key: "+key, nil)
+			return value, nil
+		}
+		locked, err = t.Cache.PutIfAbsent(lockKey, token, t.lockTTL)
+	}
+	if err != nil {
+		logger.Warn(literals.LazyJack, "error while locking computation of result for key: "+key, err, nil)
+	}
+
+	if locked {
+		defer func() {
+			_, err := t.Cache.DeleteIfValue(lockKey, token)
+			if err != nil {
+				logger.Warn(literals.LazyJack, "error while unlocking computation of result for key: "+key, err, nil)
+			}
+		}()
+
+		// another instance may have computed the value just before the lock was acquired
+		if value, fresh, err := t.get(key); err == nil && fresh {
+			return value, nil
+		}
+	}
+
+	value, err := compute(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// save it in cache
+	_ = t.put(key, value)
+	return value, nil
+}
+
+// put saves value of the key in cache, value expires after ttl but it is kept in cache for stale ttl more
+func (t *flightPathModel) put(key, value string) error {
+	resultBytes, err := json.Marshal(cachedResult{Value: json.RawMessage(value), ExpiresAt: t.now().Add(t.ttl).UnixNano()})
+	if err != nil {
+		logger.Warn(literals.LazyJack, "error while saving data in cache for key: "+key, err, nil)
+		return err
+	}
+	return t.Cache.Put(key, string(resultBytes), t.ttl+t.staleTTL)
 }
 
-// generateCacheKey generates unique key for input data
-func generateCacheKey(data flightpath.LazyJackRequest) (string, error) {
-	// add schedules to the key as well
-	schedulesJSON, err := json.Marshal(data.Schedules)
+// get gets value of the key from cache along with whether it is fresh i.e. it has not expired
+func (t *flightPathModel) get(key string) (string, bool, error) {
+	resultJSON, err := t.Cache.Get(key)
 	if err != nil {
-		logger.Warn(literals.LazyJack, "error while marshalling schedules for generating key", err, nil)
+		return "", false, err
+	}
+
+	var result cachedResult
+	err = json.Unmarshal([]byte(resultJSON), &result)
+	if err != nil {
+		return "", false, err
+	}
+	return string(result.Value), t.now().UnixNano() < result.ExpiresAt, nil
+}
+
+// newLockToken generates a random token which identifies the owner of a lock
+func newLockToken() string {
+	tokenBytes := make([]byte, 16)
+	_, _ = rand.Read(tokenBytes)
+	return hex.EncodeToString(tokenBytes)
+}
+
+// generateCacheKey generates unique key for input data, suffix tells which kind of result is stored against the key
+// key is the digest of the canonical input data, so that keys have fixed length and same search in any order of schedules has same key
+func generateCacheKey(data flightpath.LazyJackRequest, suffix string) (string, error) {
+	// stored flight schedules are identified by their version, so that they are not hashed on every request
+	var schedules []string
+	if data.StoredSchedulesVersion != nil {
+		schedules = []string{"stored-schedules-" + strconv.FormatInt(*data.StoredSchedulesVersion, 10)}
+	} else {
+		var err error
+		schedules, err = canonicalizeSchedules(data.Schedules)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// every other field of the request is kept as it is, json marshalling sorts map keys, hence same maps always generate same key
+	// explanation of the search does not change the itineraries, hence it is not part of the key
+	data.Schedules = nil
+	data.Explain = false
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		logger.Warn(literals.LazyJack, "error while marshalling request for generating key", err, nil)
 		return "", err
 	}
 
-	key := data.TripPlan.StartCity + "_" + data.TripPlan.EndCity + "_" + strconv.FormatInt(data.PreferredTime, 10) + string(schedulesJSON)
-	base64key := base64.StdEncoding.EncodeToString([]byte(key))
-	return base64key + flightPathSuffix, nil
+	digest := sha256.New()
+	digest.Write(dataJSON)
+	for _, schedule := range schedules {
+		digest.Write([]byte("\n" + schedule))
+	}
+	return cacheKeyPrefix + strconv.Itoa(cacheKeyVersion) + "-" + hex.EncodeToString(digest.Sum(nil)) + suffix, nil
+}
+
+// canonicalizeSchedules gets json of every flight schedule with city names without surrounding spaces, sorted and without duplicates
+// flights are searched in order of their timestamps irrespective of their order in the request and duplicate flights are ignored,
+// hence canonical schedules have same itineraries as the schedules
+func canonicalizeSchedules(schedules []*flightpath.FlightDetail) ([]string, error) {
+	canonicalSchedules := make([]string, 0, len(schedules))
+	for _, schedule := range schedules {
+		if schedule != nil {
+			canonicalSchedule := *schedule
+			canonicalSchedule.Departure = canonicalizeScheduleDetail(schedule.Departure)
+			canonicalSchedule.Arrival = canonicalizeScheduleDetail(schedule.Arrival)
+			schedule = &canonicalSchedule
+		}
+
+		scheduleJSON, err := json.Marshal(schedule)
+		if err != nil {
+			logger.Warn(literals.LazyJack, "error while marshalling schedules for generating key", err, nil)
+			return nil, err
+		}
+		canonicalSchedules = append(canonicalSchedules, string(scheduleJSON))
+	}
+
+	sort.Strings(canonicalSchedules)
+	uniqueSchedules := canonicalSchedules[:0]
+	for i, schedule := range canonicalSchedules {
+		if i == 0 || schedule != canonicalSchedules[i-1] {
+			uniqueSchedules = append(uniqueSchedules, schedule)
+		}
+	}
+	return uniqueSchedules, nil
+}
+
+// canonicalizeScheduleDetail gets copy of the schedule detail with city name without surrounding spaces
+// cities are already normalized against the city catalog by the handlers, which keeps the names which are not in the catalog as they are
+func canonicalizeScheduleDetail(detail *flightpath.ScheduleDetail) *flightpath.ScheduleDetail {
+	if detail == nil {
+		return nil
+	}
+	canonicalDetail := *detail
+	canonicalDetail.City = strings.TrimSpace(detail.City)
+	return &canonicalDetail
 }
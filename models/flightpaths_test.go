@@ -1,10 +1,15 @@
 package models
 
 import (
+	"context"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+	"github.com/somprabhsharma/the-lazy-traveler/models/cache"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestFlightPaths(t *testing.T) {
@@ -14,7 +19,7 @@ func TestFlightPaths(t *testing.T) {
 
 var _ = Describe("models", func() {
 	Context("##flightpaths", func() {
-		dao := NewDao()
+		dao := NewDaoWithCache(cache.NewMemory(0))
 		data := flightpath.LazyJackRequest{
 			Schedules: []*flightpath.FlightDetail{
 				{
@@ -45,7 +50,7 @@ var _ = Describe("models", func() {
 			},
 		}
 
-		It("should save shortest path in redis cache", func() {
+		It("should save shortest path in cache", func() {
 			_ = dao.FlightPathModel.Put(shortestPath, data)
 			val, _ := dao.FlightPathModel.Get(data)
 			Expect(val).ShouldNot(BeNil())
@@ -56,7 +61,7 @@ var _ = Describe("models", func() {
 			Expect(val[1].Timestamp).To(Equal(int64(10)))
 		})
 
-		It("should get shortest path from redis cache", func() {
+		It("should get shortest path from cache", func() {
 			_ = dao.FlightPathModel.Put(shortestPath, data)
 			val, _ := dao.FlightPathModel.Get(data)
 			Expect(val).ShouldNot(BeNil())
@@ -66,5 +71,283 @@ var _ = Describe("models", func() {
 			Expect(val[1].City).To(Equal("Z"))
 			Expect(val[1].Timestamp).To(Equal(int64(10)))
 		})
+
+		It("should save itineraries in cache", func() {
+			data.K = 2
+			itineraries := []*flightpath.Itinerary{
+				{
+					Legs: []*flightpath.Leg{
+						{
+							Departure:      shortestPath[0],
+							Arrival:        shortestPath[1],
+							FlightDuration: 9,
+						},
+					},
+					TotalDuration: 9,
+					InAirTime:     9,
+				},
+			}
+			_ = dao.FlightPathModel.PutItineraries(itineraries, data)
+			val, _ := dao.FlightPathModel.GetItineraries(data)
+			Expect(val).ShouldNot(BeNil())
+			Expect(len(val)).To(Equal(1))
+			Expect(len(val[0].Legs)).To(Equal(1))
+			Expect(val[0].Legs[0].Departure.City).To(Equal("A"))
+			Expect(val[0].Legs[0].Arrival.City).To(Equal("Z"))
+			Expect(val[0].TotalDuration).To(Equal(int64(9)))
+		})
+	})
+
+	Context("##coalescing", func() {
+		data := flightpath.LazyJackRequest{
+			Schedules: []*flightpath.FlightDetail{
+				{
+					Departure: &flightpath.ScheduleDetail{City: "A", Timestamp: 1},
+					Arrival:   &flightpath.ScheduleDetail{City: "Z", Timestamp: 10},
+				},
+			},
+			TripPlan: &flightpath.TripDetail{StartCity: "A", EndCity: "Z"},
+		}
+		itineraries := func(duration int64) []*flightpath.Itinerary {
+			return []*flightpath.Itinerary{{TotalDuration: duration}}
+		}
+
+		It("should compute result only once for concurrent requests with same key", func() {
+			model := newFlightPathModel(cache.NewMemory(0))
+			var computations int32
+			release := make(chan bool)
+			compute := func(ctx context.Context) ([]*flightpath.Itinerary, error) {
+				atomic.AddInt32(&computations, 1)
+				<-release
+				return itineraries(9), nil
+			}
+
+			var wg sync.WaitGroup
+			results := make([][]*flightpath.Itinerary, 10)
+			for i := range results {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					results[i], _ = model.GetOrComputeItineraries(context.Background(), data, compute)
+				}(i)
+			}
+			time.Sleep(100 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			Expect(atomic.LoadInt32(&computations)).To(Equal(int32(1)))
+			for _, result := range results {
+				Expect(result).To(Equal(itineraries(9)))
+			}
+		})
+
+		It("should wait for result computed by another instance holding the lock", func() {
+			c := cache.NewMemory(0)
+			model, otherModel := newFlightPathModel(c), newFlightPathModel(c)
+			key, _ := generateCacheKey(data, itinerariesSuffix)
+			locked, _ := c.PutIfAbsent(key+lockSuffix, "other-instance", time.Minute)
+			Expect(locked).To(BeTrue())
+
+			go func() {
+				time.Sleep(100 * time.Millisecond)
+				_ = otherModel.PutItineraries(itineraries(7), data)
+				_, _ = c.DeleteIfValue(key+lockSuffix, "other-instance")
+			}()
+			computed := false
+			result, err := model.GetOrComputeItineraries(context.Background(), data, func(ctx context.Context) ([]*flightpath.Itinerary, error) {
+				computed = true
+				return itineraries(9), nil
+			})
+			Expect(err).Should(BeNil())
+			Expect(result).To(Equal(itineraries(7)))
+			Expect(computed).To(BeFalse())
+		})
+
+		It("should stop waiting once context of the caller is done and cancel computation once no caller waits for it", func() {
+			model := newFlightPathModel(cache.NewMemory(0))
+			canceled := make(chan bool)
+			release := make(chan bool)
+			compute := func(ctx context.Context) ([]*flightpath.Itinerary, error) {
+				select {
+				case <-ctx.Done():
+					close(canceled)
+					return nil, ctx.Err()
+				case <-release:
+					return itineraries(9), nil
+				}
+			}
+
+			// computation keeps running for the caller which is still waiting
+			waitingCtx, stopWaiting := context.WithCancel(context.Background())
+			done := make(chan bool)
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				_, err := model.GetOrComputeItineraries(waitingCtx, data, compute)
+				Expect(err).To(Equal(context.Canceled))
+			}()
+			time.Sleep(50 * time.Millisecond)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			result, err := model.GetOrComputeItineraries(ctx, data, compute)
+			Expect(result).Should(BeNil())
+			Expect(err).To(Equal(context.DeadlineExceeded))
+			Consistently(canceled, 100*time.Millisecond).ShouldNot(BeClosed())
+
+			stopWaiting()
+			Eventually(done).Should(BeClosed())
+			Eventually(canceled).Should(BeClosed())
+
+			// next caller computes again instead of getting result of the canceled computation
+			result, err = model.GetOrComputeItineraries(context.Background(), data, func(ctx context.Context) ([]*flightpath.Itinerary, error) {
+				return itineraries(7), nil
+			})
+			Expect(err).Should(BeNil())
+			Expect(result).To(Equal(itineraries(7)))
+		})
+
+		It("should stop waiting for result computed by another instance once context is done", func() {
+			c := cache.NewMemory(0)
+			model := newFlightPathModel(c)
+			key, _ := generateCacheKey(data, itinerariesSuffix)
+			_, _ = c.PutIfAbsent(key+lockSuffix, "other-instance", time.Minute)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			_, err := model.GetOrComputeItineraries(ctx, data, func(ctx context.Context) ([]*flightpath.Itinerary, error) {
+				return itineraries(9), nil
+			})
+			Expect(err).To(Equal(context.DeadlineExceeded))
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		})
+
+		It("should serve expired result while refreshing it in background if stale ttl is set", func() {
+			model := newFlightPathModel(cache.NewMemory(0))
+			model.staleTTL = time.Hour
+			now := time.Now()
+			model.now = func() time.Time { return now }
+			_ = model.PutItineraries(itineraries(7), data)
+			now = now.Add(flightPathTTL + time.Minute)
+
+			refreshed := make(chan bool)
+			result, err := model.GetOrComputeItineraries(context.Background(), data, func(ctx context.Context) ([]*flightpath.Itinerary, error) {
+				defer close(refreshed)
+				return itineraries(9), nil
+			})
+			Expect(err).Should(BeNil())
+			Expect(result).To(Equal(itineraries(7)))
+
+			Eventually(refreshed).Should(BeClosed())
+			Eventually(func() []*flightpath.Itinerary {
+				result, _ := model.GetItineraries(data)
+				return result
+			}).Should(Equal(itineraries(9)))
+		})
+
+		It("should wait for the stale result being refreshed in background once it is closed", func() {
+			model := newFlightPathModel(cache.NewMemory(0))
+			model.staleTTL = time.Hour
+			expiredAt := time.Now().Add(flightPathTTL + time.Minute)
+			model.now = func() time.Time { return expiredAt.Add(-flightPathTTL - time.Minute) }
+			_ = model.PutItineraries(itineraries(7), data)
+			model.now = func() time.Time { return expiredAt }
+
+			release := make(chan bool)
+			result, _ := model.GetOrComputeItineraries(context.Background(), data, func(ctx context.Context) ([]*flightpath.Itinerary, error) {
+				<-release
+				return itineraries(9), nil
+			})
+			Expect(result).To(Equal(itineraries(7)))
+
+			closed := make(chan bool)
+			go func() {
+				model.Close()
+				close(closed)
+			}()
+			Consistently(closed, 100*time.Millisecond).ShouldNot(BeClosed())
+			close(release)
+			Eventually(closed).Should(BeClosed())
+			result, _ = model.GetItineraries(data)
+			Expect(result).To(Equal(itineraries(9)))
+
+			// stale result is not refreshed once the model is closed
+			model.now = func() time.Time { return expiredAt.Add(flightPathTTL + time.Minute) }
+			refreshed := false
+			result, _ = model.GetOrComputeItineraries(context.Background(), data, func(ctx context.Context) ([]*flightpath.Itinerary, error) {
+				refreshed = true
+				return itineraries(11), nil
+			})
+			Expect(result).To(Equal(itineraries(9)))
+			model.refreshes.Wait()
+			Expect(refreshed).To(BeFalse())
+		})
+
+		It("should compute expired result again if stale ttl is not set", func() {
+			model := newFlightPathModel(cache.NewMemory(0))
+			model.staleTTL = 0
+			now := time.Now()
+			model.now = func() time.Time { return now }
+			_ = model.PutItineraries(itineraries(7), data)
+			now = now.Add(flightPathTTL + time.Minute)
+
+			result, err := model.GetOrComputeItineraries(context.Background(), data, func(ctx context.Context) ([]*flightpath.Itinerary, error) {
+				return itineraries(9), nil
+			})
+			Expect(err).Should(BeNil())
+			Expect(result).To(Equal(itineraries(9)))
+		})
+	})
+
+	Context("##cachekeys", func() {
+		first := &flightpath.FlightDetail{
+			Departure: &flightpath.ScheduleDetail{City: "A", Timestamp: 1},
+			Arrival:   &flightpath.ScheduleDetail{City: "B", Timestamp: 3},
+		}
+		second := &flightpath.FlightDetail{
+			Departure: &flightpath.ScheduleDetail{City: "B", Timestamp: 5},
+			Arrival:   &flightpath.ScheduleDetail{City: "Z", Timestamp: 8},
+		}
+		request := func(schedules ...*flightpath.FlightDetail) flightpath.LazyJackRequest {
+			return flightpath.LazyJackRequest{
+				Schedules: schedules,
+				TripPlan:  &flightpath.TripDetail{StartCity: "A", EndCity: "Z"},
+			}
+		}
+
+		It("should generate same key for schedules in any order, with duplicates or with spaces around city names", func() {
+			key, err := generateCacheKey(request(first, second), itinerariesSuffix)
+			Expect(err).Should(BeNil())
+
+			spacedSecond := &flightpath.FlightDetail{
+				Departure: &flightpath.ScheduleDetail{City: " B", Timestamp: 5},
+				Arrival:   &flightpath.ScheduleDetail{City: "Z ", Timestamp: 8},
+			}
+			otherKey, err := generateCacheKey(request(second, first, spacedSecond), itinerariesSuffix)
+			Expect(err).Should(BeNil())
+			Expect(otherKey).To(Equal(key))
+			Expect(second.Departure.City).To(Equal("B"))
+			Expect(spacedSecond.Departure.City).To(Equal(" B"))
+		})
+
+		It("should generate fixed length versioned key irrespective of number of schedules", func() {
+			key, _ := generateCacheKey(request(first), itinerariesSuffix)
+			otherKey, _ := generateCacheKey(request(first, second), itinerariesSuffix)
+			Expect(key).To(HavePrefix(cacheKeyPrefix + "3-"))
+			Expect(key).To(HaveSuffix(itinerariesSuffix))
+			Expect(len(otherKey)).To(Equal(len(key)))
+			Expect(otherKey).NotTo(Equal(key))
+		})
+
+		It("should generate different keys for different searches over same schedules", func() {
+			key, _ := generateCacheKey(request(first, second), itinerariesSuffix)
+			data := request(first, second)
+			data.K = 2
+			otherKey, _ := generateCacheKey(data, itinerariesSuffix)
+			Expect(otherKey).NotTo(Equal(key))
+			otherKey, _ = generateCacheKey(request(first, second), flightPathSuffix)
+			Expect(otherKey).NotTo(Equal(key))
+		})
 	})
 })
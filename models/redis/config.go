@@ -0,0 +1,143 @@
+package redis
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"github.com/go-redis/redis"
+	"github.com/somprabhsharma/the-lazy-traveler/constants"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
+	"io/ioutil"
+)
+
+// newUniversalClient creates redis client of the connection mode configured in the environment
+func newUniversalClient() (redis.UniversalClient, error) {
+	env := constants.Env
+	if env.RedisPoolSize < 0 || env.RedisDialTimeout < 0 || env.RedisReadTimeout < 0 || env.RedisWriteTimeout < 0 {
+		return nil, errors.New("invalid redis config: REDIS_POOL_SIZE, REDIS_DIAL_TIMEOUT, REDIS_READ_TIMEOUT and REDIS_WRITE_TIMEOUT cannot be negative")
+	}
+
+	tlsConfig, err := getTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	switch env.RedisMode {
+	case literals.RedisStandalone:
+		opt, err := getStandaloneOptions(tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+		return redis.NewClient(opt), nil
+
+	case literals.RedisSentinel:
+		if env.RedisMasterName == "" || len(env.RedisAddrs) == 0 {
+			return nil, errors.New("invalid redis config: sentinel mode needs REDIS_MASTER_NAME and REDIS_ADDRS of the sentinels")
+		}
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:      env.RedisMasterName,
+			SentinelAddrs:   env.RedisAddrs,
+			Password:        env.RedisPassword,
+			DB:              env.RedisDB,
+			OnConnect:       onConnect,
+			MaxRetries:      maxRetries,
+			MaxRetryBackoff: maxRetryBackOff,
+			DialTimeout:     env.RedisDialTimeout,
+			ReadTimeout:     env.RedisReadTimeout,
+			WriteTimeout:    env.RedisWriteTimeout,
+			PoolSize:        env.RedisPoolSize,
+			TLSConfig:       tlsConfig,
+		}), nil
+
+	case literals.RedisCluster:
+		if len(env.RedisAddrs) == 0 {
+			return nil, errors.New("invalid redis config: cluster mode needs REDIS_ADDRS of the cluster nodes")
+		}
+		if env.RedisDB != 0 {
+			return nil, errors.New("invalid redis config: cluster mode does not support REDIS_DB")
+		}
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:           env.RedisAddrs,
+			Password:        env.RedisPassword,
+			OnConnect:       onConnect,
+			MaxRetries:      maxRetries,
+			MaxRetryBackoff: maxRetryBackOff,
+			DialTimeout:     env.RedisDialTimeout,
+			ReadTimeout:     env.RedisReadTimeout,
+			WriteTimeout:    env.RedisWriteTimeout,
+			PoolSize:        env.RedisPoolSize,
+			TLSConfig:       tlsConfig,
+		}), nil
+	}
+	return nil, errors.New("invalid redis config: REDIS_MODE must be standalone, sentinel or cluster")
+}
+
+// getStandaloneOptions gets options of standalone redis, which is at host:port in dev environment and at redis url otherwise
+// password, db and tls config of the environment override the ones in redis url
+func getStandaloneOptions(tlsConfig *tls.Config) (*redis.Options, error) {
+	env := constants.Env
+	opt := &redis.Options{
+		Addr: env.RedisURL,
+		DB:   env.RedisDB,
+	}
+	if env.Environment != "dev" {
+		var err error
+		opt, err = redis.ParseURL(env.RedisURL)
+		if err != nil {
+			return nil, errors.New("invalid redis config: REDIS_URL must be a redis url e.g. redis://:password@host:6379/0, " + err.Error())
+		}
+	}
+
+	if env.RedisPassword != "" {
+		opt.Password = env.RedisPassword
+	}
+	if env.RedisDB != 0 {
+		opt.DB = env.RedisDB
+	}
+	if tlsConfig != nil {
+		opt.TLSConfig = tlsConfig
+	}
+	opt.OnConnect = onConnect
+	opt.MaxRetries = maxRetries
+	opt.MaxRetryBackoff = maxRetryBackOff
+	opt.DialTimeout = env.RedisDialTimeout
+	opt.ReadTimeout = env.RedisReadTimeout
+	opt.WriteTimeout = env.RedisWriteTimeout
+	opt.PoolSize = env.RedisPoolSize
+	return opt, nil
+}
+
+// getTLSConfig gets tls config of the connections to redis, it is nil if tls is not enabled
+// certificate of redis is verified against the custom CA if it is given, otherwise against the CAs of the system
+func getTLSConfig() (*tls.Config, error) {
+	env := constants.Env
+	if !env.RedisTLS {
+		if env.RedisTLSCAFile != "" || env.RedisTLSServerName != "" {
+			return nil, errors.New("invalid redis config: REDIS_TLS_CA_FILE and REDIS_TLS_SERVER_NAME need REDIS_TLS=true")
+		}
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: env.RedisTLSServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if env.RedisTLSCAFile != "" {
+		caPEM, err := ioutil.ReadFile(env.RedisTLSCAFile)
+		if err != nil {
+			return nil, errors.New("invalid redis config: REDIS_TLS_CA_FILE cannot be read, " + err.Error())
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("invalid redis config: REDIS_TLS_CA_FILE does not have any PEM encoded certificate")
+		}
+	}
+	return tlsConfig, nil
+}
+
+// onConnect logs every new connection to redis
+func onConnect(conn *redis.Conn) error {
+	logger.Info("Redis", "successfully connected to redis.", nil)
+	return nil
+}
@@ -0,0 +1,102 @@
+package redis
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/somprabhsharma/the-lazy-traveler/constants"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+var _ = Describe("models", func() {
+	Context("##config", func() {
+		env := constants.Env
+		AfterEach(func() {
+			constants.Env = env
+		})
+
+		expectConfigError := func(message string) {
+			client, err := NewClient()
+			Expect(client).Should(BeNil())
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring(message))
+		}
+
+		It("should create client of every connection mode with tls", func() {
+			constants.Env.RedisTLS = true
+			constants.Env.RedisTLSServerName = "redis.example.com"
+			constants.Env.RedisPoolSize = 5
+
+			client, err := NewClient()
+			Expect(err).Should(BeNil())
+			Expect(client).ShouldNot(BeNil())
+
+			constants.Env.RedisMode = literals.RedisSentinel
+			constants.Env.RedisMasterName = "master"
+			constants.Env.RedisAddrs = []string{"localhost:26379", "localhost:26380"}
+			client, err = NewClient()
+			Expect(err).Should(BeNil())
+			Expect(client).ShouldNot(BeNil())
+
+			constants.Env.RedisMode = literals.RedisCluster
+			client, err = NewClient()
+			Expect(err).Should(BeNil())
+			Expect(client).ShouldNot(BeNil())
+		})
+
+		It("should fail on unknown connection mode", func() {
+			constants.Env.RedisMode = "replicated"
+			expectConfigError("REDIS_MODE must be standalone, sentinel or cluster")
+		})
+
+		It("should fail on invalid redis url outside dev environment", func() {
+			constants.Env.Environment = "production"
+			constants.Env.RedisURL = "http://localhost:6379"
+			expectConfigError("REDIS_URL must be a redis url")
+		})
+
+		It("should fail on sentinel mode without master name or sentinels", func() {
+			constants.Env.RedisMode = literals.RedisSentinel
+			constants.Env.RedisAddrs = []string{"localhost:26379"}
+			expectConfigError("sentinel mode needs REDIS_MASTER_NAME and REDIS_ADDRS")
+
+			constants.Env.RedisMasterName = "master"
+			constants.Env.RedisAddrs = nil
+			expectConfigError("sentinel mode needs REDIS_MASTER_NAME and REDIS_ADDRS")
+		})
+
+		It("should fail on cluster mode without nodes or with db", func() {
+			constants.Env.RedisMode = literals.RedisCluster
+			expectConfigError("cluster mode needs REDIS_ADDRS")
+
+			constants.Env.RedisAddrs = []string{"localhost:7000"}
+			constants.Env.RedisDB = 1
+			expectConfigError("cluster mode does not support REDIS_DB")
+		})
+
+		It("should fail on negative pool size or timeouts", func() {
+			constants.Env.RedisReadTimeout = -1
+			expectConfigError("cannot be negative")
+		})
+
+		It("should fail on tls options without tls", func() {
+			constants.Env.RedisTLSCAFile = "ca.pem"
+			expectConfigError("need REDIS_TLS=true")
+		})
+
+		It("should fail on custom CA which cannot be read or does not have certificates", func() {
+			constants.Env.RedisTLS = true
+			constants.Env.RedisTLSCAFile = "missing-ca.pem"
+			expectConfigError("REDIS_TLS_CA_FILE cannot be read")
+
+			dir, _ := ioutil.TempDir("", "redis-config")
+			defer os.RemoveAll(dir)
+			caFile := filepath.Join(dir, "ca.pem")
+			_ = ioutil.WriteFile(caFile, []byte("not a certificate"), 0600)
+			constants.Env.RedisTLSCAFile = caFile
+			expectConfigError("REDIS_TLS_CA_FILE does not have any PEM encoded certificate")
+		})
+	})
+})
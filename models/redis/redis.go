@@ -8,60 +8,45 @@ import (
 )
 
 const (
-	maxRetries      = 10   //maximum number of retries if connection is lost
-	maxRetryBackOff = 3000 //time after which each retry will happen
+	// a command can take retries times dial or read timeout plus back off if redis is unreachable,
+	// hence the circuit breaker of redis gives up on a command once its call timeout passes, so that requests do not wait for the retries
+	maxRetries      = 3                       //maximum number of retries if connection is lost
+	maxRetryBackOff = 3000 * time.Millisecond //maximum time after which each retry will happen
+
+	// deleteIfValueScript deletes the key only if it has given value
+	deleteIfValueScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+	// putFieldScript puts value of the field of the hash and increments version of the hash
+	putFieldScript = `redis.call("hset", KEYS[1], ARGV[1], ARGV[2]) return redis.call("incr", KEYS[2])`
+	// putFieldIfPresentScript puts value of the field of the hash and increments version of the hash only if the field is present
+	putFieldIfPresentScript = `if redis.call("hexists", KEYS[1], ARGV[1]) == 1 then redis.call("hset", KEYS[1], ARGV[1], ARGV[2]) redis.call("incr", KEYS[2]) return 1 else return 0 end`
+	// deleteFieldScript deletes the field of the hash and increments version of the hash only if the field is present
+	deleteFieldScript = `if redis.call("hdel", KEYS[1], ARGV[1]) == 1 then redis.call("incr", KEYS[2]) return 1 else return 0 end`
 )
 
 // Client redis client
 type Client struct {
-	client *redis.Client
+	client redis.UniversalClient
 }
 
-//NewClient initializes redis client with proper configuration
-func NewClient() *Client {
-	var redisClient = &Client{}
-
-	// initialize redis client for dev environment
-	if constants.Env.Environment == "dev" {
-		redisClient.client = getLocalClient()
-		return redisClient
-	}
-
-	opt, err := redis.ParseURL(constants.Env.RedisURL)
+// NewClient initializes redis client of the connection mode configured in the environment i.e. standalone, sentinel or cluster
+// error is returned if the configuration is invalid, so that the service does not start with a redis it can never connect to
+func NewClient() (*Client, error) {
+	client, err := newUniversalClient()
 	if err != nil {
-		logger.Err("Redis", "Error while parsing redis url", err, nil)
+		logger.Err("Redis", "Error while configuring redis", err, constants.Env.RedisMode)
+		return nil, err
 	}
+	return &Client{client: client}, nil
+}
 
-	redisClient.client = redis.NewClient(&redis.Options{
-		Addr:     opt.Addr,
-		DB:       opt.DB,
-		Password: opt.Password,
-		OnConnect: func(conn *redis.Conn) error {
-			logger.Info("Redis", "successfully connected to redis.", nil)
-			return nil
-		},
-		MaxRetries:      maxRetries,
-		MaxRetryBackoff: maxRetryBackOff,
-	})
-
-	_, err = redisClient.client.Ping().Result()
-	if err != nil {
-		logger.Err("Redis", "Error while connecting to redis.", err, nil)
-	}
-	return redisClient
+// Ping checks if redis is reachable
+func (r *Client) Ping() error {
+	return r.client.Ping().Err()
 }
 
-// getLocalClient gets local redis client
-func getLocalClient() *redis.Client {
-	return redis.NewClient(&redis.Options{
-		Addr: constants.Env.RedisURL,
-		OnConnect: func(conn *redis.Conn) error {
-			logger.Info("Redis", "successfully connected to redis.", nil)
-			return nil
-		},
-		MaxRetries:      maxRetries,
-		MaxRetryBackoff: maxRetryBackOff,
-	})
+// Close closes all the connections to redis
+func (r *Client) Close() error {
+	return r.client.Close()
 }
 
 // Put value corresponding to key in redis
@@ -81,3 +66,68 @@ func (r *Client) Delete(key string) error {
 	_, err := r.client.Del(key).Result()
 	return err
 }
+
+// PutIfAbsent puts value corresponding to key in redis only if key is not present, returns false if key was present
+func (r *Client) PutIfAbsent(key, value string, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(key, value, ttl).Result()
+}
+
+// DeleteIfValue deletes value for given key only if it is same as given value, returns false if it was not deleted
+// comparison and deletion are done by a script, so that value cannot change in between
+func (r *Client) DeleteIfValue(key, value string) (bool, error) {
+	count, err := r.client.Eval(deleteIfValueScript, []string{key}, value).Int64()
+	return count > 0, err
+}
+
+// PutField puts value corresponding to field of the hash stored at key in redis
+// value is put and version of the hash is incremented by a script, so that the hash never changes without its version
+func (r *Client) PutField(key, field, value string) error {
+	err := r.client.Eval(putFieldScript, []string{key, versionKey(key)}, field, value).Err()
+	return err
+}
+
+// PutFieldIfPresent puts value corresponding to field of the hash stored at key only if field is present, returns false if it was not
+// presence is checked, value is put and version of the hash is incremented by a script, so that field cannot be deleted in between
+func (r *Client) PutFieldIfPresent(key, field, value string) (bool, error) {
+	count, err := r.client.Eval(putFieldIfPresentScript, []string{key, versionKey(key)}, field, value).Int64()
+	return count > 0, err
+}
+
+// GetVersion gets version of the hash stored at key, which is zero until any field of the hash is changed
+func (r *Client) GetVersion(key string) (int64, error) {
+	version, err := r.client.Get(versionKey(key)).Int64()
+	if IsNotFound(err) {
+		return 0, nil
+	}
+	return version, err
+}
+
+// GetField gets value of the field of the hash stored at key
+func (r *Client) GetField(key, field string) (string, error) {
+	value, err := r.client.HGet(key, field).Result()
+	return value, err
+}
+
+// GetAllFields gets all the fields and their values of the hash stored at key
+func (r *Client) GetAllFields(key string) (map[string]string, error) {
+	values, err := r.client.HGetAll(key).Result()
+	return values, err
+}
+
+// DeleteField deletes the field of the hash stored at key, returns false if field was not present
+// field is deleted and version of the hash is incremented by a script, so that the hash never changes without its version
+func (r *Client) DeleteField(key, field string) (bool, error) {
+	count, err := r.client.Eval(deleteFieldScript, []string{key, versionKey(key)}, field).Int64()
+	return count > 0, err
+}
+
+// versionKey gets key of the version of the hash stored at key
+// key of the hash is the hash tag of the version key, so that both of them are in the same slot of a redis cluster as a script needs
+func versionKey(key string) string {
+	return "{" + key + "}-version"
+}
+
+// IsNotFound tells if the error is returned because key or field is not present in redis
+func IsNotFound(err error) bool {
+	return err == redis.Nil
+}
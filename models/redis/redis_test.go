@@ -14,7 +14,13 @@ func TestRedis(t *testing.T) {
 
 var _ = Describe("models", func() {
 	Context("##redis", func() {
-		client := NewClient()
+		client, _ := NewClient()
+		BeforeEach(func() {
+			if client.Ping() != nil {
+				Skip("redis is not reachable")
+			}
+		})
+
 		It("should save value in redis cache", func() {
 			_ = client.Put("key-123", "value", time.Minute)
 			val, _ := client.Get("key-123")
@@ -35,5 +41,52 @@ var _ = Describe("models", func() {
 			val, _ = client.Get("key-123")
 			Expect(val).To(Equal(""))
 		})
+
+		It("should save, return and delete field of a hash in redis cache", func() {
+			_ = client.PutField("hash-123", "field", "value")
+			val, _ := client.GetField("hash-123", "field")
+			Expect(val).To(Equal("value"))
+			values, _ := client.GetAllFields("hash-123")
+			Expect(values).To(HaveKeyWithValue("field", "value"))
+			deleted, _ := client.DeleteField("hash-123", "field")
+			Expect(deleted).To(BeTrue())
+			_, err := client.GetField("hash-123", "field")
+			Expect(IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should put field of a hash in redis cache only if it is present", func() {
+			put, _ := client.PutFieldIfPresent("hash-456", "field", "value")
+			Expect(put).To(BeFalse())
+			_, err := client.GetField("hash-456", "field")
+			Expect(IsNotFound(err)).To(BeTrue())
+
+			_ = client.PutField("hash-456", "field", "value")
+			put, _ = client.PutFieldIfPresent("hash-456", "field", "new-value")
+			Expect(put).To(BeTrue())
+			val, _ := client.GetField("hash-456", "field")
+			Expect(val).To(Equal("new-value"))
+			_, _ = client.DeleteField("hash-456", "field")
+		})
+
+		It("should increment version of a hash in redis cache along with every change of its fields", func() {
+			_ = client.Delete("hash-789")
+			_ = client.Delete(versionKey("hash-789"))
+			version, _ := client.GetVersion("hash-789")
+			Expect(version).To(Equal(int64(0)))
+
+			put, _ := client.PutFieldIfPresent("hash-789", "field", "value")
+			Expect(put).To(BeFalse())
+			deleted, _ := client.DeleteField("hash-789", "field")
+			Expect(deleted).To(BeFalse())
+			version, _ = client.GetVersion("hash-789")
+			Expect(version).To(Equal(int64(0)))
+
+			_ = client.PutField("hash-789", "field", "value")
+			_, _ = client.PutFieldIfPresent("hash-789", "field", "new-value")
+			_, _ = client.DeleteField("hash-789", "field")
+			version, _ = client.GetVersion("hash-789")
+			Expect(version).To(Equal(int64(3)))
+			_ = client.Delete(versionKey("hash-789"))
+		})
 	})
 })
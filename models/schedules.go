@@ -0,0 +1,168 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+	"github.com/somprabhsharma/the-lazy-traveler/models/cache"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
+	"sort"
+	"sync"
+)
+
+const (
+	// all the stored flight schedules are kept in one hash with flight id as field
+	// version of the hash is the version of the stored flight schedules, which is incremented along with every change of the schedules
+	schedulesKey = "the-lazy-traveler-schedules"
+)
+
+type scheduleModel struct {
+	Cache cache.Cache
+
+	mu       sync.Mutex
+	snapshot *scheduleSnapshot // stored flight schedules last listed by the process
+}
+
+// scheduleSnapshot is the list of stored flight schedules at a version of the store
+type scheduleSnapshot struct {
+	version   int64
+	schedules []*flightpath.FlightDetail
+}
+
+func newScheduleModel(c cache.Cache) *scheduleModel {
+	return &scheduleModel{
+		Cache: c,
+	}
+}
+
+// Put creates or replaces the flight schedule against its id in the store
+func (s *scheduleModel) Put(schedule *flightpath.FlightDetail) error {
+	scheduleBytes, err := json.Marshal(schedule)
+	if err != nil {
+		logger.Warn(literals.ScheduleStore, "error while marshalling flight schedule with id: "+schedule.ID, err, nil)
+		return err
+	}
+
+	return s.Cache.PutField(schedulesKey, schedule.ID, string(scheduleBytes))
+}
+
+// PutIfPresent replaces the flight schedule against its id in the store, returns false if there is no such flight schedule
+// schedule is replaced atomically, so that a schedule deleted meanwhile is not created again
+func (s *scheduleModel) PutIfPresent(schedule *flightpath.FlightDetail) (bool, error) {
+	scheduleBytes, err := json.Marshal(schedule)
+	if err != nil {
+		logger.Warn(literals.ScheduleStore, "error while marshalling flight schedule with id: "+schedule.ID, err, nil)
+		return false, err
+	}
+
+	return s.Cache.PutFieldIfPresent(schedulesKey, schedule.ID, string(scheduleBytes))
+}
+
+// Get gets flight schedule with given id from the store, returns nil if there is no such flight schedule
+func (s *scheduleModel) Get(id string) (*flightpath.FlightDetail, error) {
+	value, err := s.Cache.GetField(schedulesKey, id)
+	if cache.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Warn(literals.ScheduleStore, "error while getting flight schedule from store for id: "+id, err, nil)
+		return nil, err
+	}
+
+	var schedule flightpath.FlightDetail
+	err = json.Unmarshal([]byte(value), &schedule)
+	if err != nil {
+		logger.Warn(literals.ScheduleStore, "error while un marshalling flight schedule obtained from store for id: "+id, err, nil)
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+// List gets all the flight schedules from the store ordered by their departure time
+func (s *scheduleModel) List() ([]*flightpath.FlightDetail, error) {
+	values, err := s.Cache.GetAllFields(schedulesKey)
+	if err != nil {
+		logger.Warn(literals.ScheduleStore, "error while getting flight schedules from store", err, nil)
+		return nil, err
+	}
+
+	schedules := make([]*flightpath.FlightDetail, 0, len(values))
+	for id, value := range values {
+		var schedule flightpath.FlightDetail
+		err = json.Unmarshal([]byte(value), &schedule)
+		if err != nil {
+			logger.Warn(literals.ScheduleStore, "error while un marshalling flight schedule obtained from store for id: "+id, err, nil)
+			return nil, err
+		}
+		schedules = append(schedules, &schedule)
+	}
+
+	// hash fields do not have any order, hence sorting them to always return the timetable in same order
+	sort.Slice(schedules, func(i, j int) bool {
+		if schedules[i].Departure.Timestamp != schedules[j].Departure.Timestamp {
+			return schedules[i].Departure.Timestamp < schedules[j].Departure.Timestamp
+		}
+		return schedules[i].ID < schedules[j].ID
+	})
+
+	return schedules, nil
+}
+
+// ListWithVersion gets all the flight schedules from the store along with the version of the store
+// schedules are listed from the store only when its version changes, otherwise schedules listed last time are returned,
+// hence returned schedules are shared and must not be modified
+func (s *scheduleModel) ListWithVersion() ([]*flightpath.FlightDetail, int64, error) {
+	version, err := s.Version()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s.mu.Lock()
+	snapshot := s.snapshot
+	s.mu.Unlock()
+	if snapshot != nil && snapshot.version == version {
+		return snapshot.schedules, version, nil
+	}
+
+	// version is read before the schedules, so listed schedules are at least as new as the version
+	schedules, err := s.List()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s.mu.Lock()
+	s.snapshot = &scheduleSnapshot{version: version, schedules: schedules}
+	s.mu.Unlock()
+	return schedules, version, nil
+}
+
+// Version gets version of the stored flight schedules, which changes whenever a flight schedule is created, updated or deleted
+func (s *scheduleModel) Version() (int64, error) {
+	version, err := s.Cache.GetVersion(schedulesKey)
+	if err != nil {
+		logger.Warn(literals.ScheduleStore, "error while getting version of flight schedules from store", err, nil)
+		return 0, err
+	}
+	return version, nil
+}
+
+// Count gets number of flight schedules in the store
+func (s *scheduleModel) Count() (int, error) {
+	values, err := s.Cache.GetAllFields(schedulesKey)
+	if err != nil {
+		logger.Warn(literals.ScheduleStore, "error while counting flight schedules in store", err, nil)
+		return 0, err
+	}
+	return len(values), nil
+}
+
+// Delete deletes flight schedule with given id from the store, returns false if there is no such flight schedule
+func (s *scheduleModel) Delete(id string) (bool, error) {
+	deleted, err := s.Cache.DeleteField(schedulesKey, id)
+	if err != nil {
+		logger.Warn(literals.ScheduleStore, "error while deleting flight schedule from store for id: "+id, err, nil)
+		return false, err
+	}
+	return deleted, nil
+}
@@ -0,0 +1,185 @@
+package models
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/somprabhsharma/the-lazy-traveler/constants"
+	"github.com/somprabhsharma/the-lazy-traveler/constants/literals"
+	"github.com/somprabhsharma/the-lazy-traveler/entities/flightpath"
+	"github.com/somprabhsharma/the-lazy-traveler/models/cache"
+)
+
+var _ = Describe("models", func() {
+	Context("##schedules", func() {
+		dao := NewDaoWithCache(cache.NewMemory(0))
+		schedule := &flightpath.FlightDetail{
+			ID: "test-flight-123",
+			Departure: &flightpath.ScheduleDetail{
+				City:      "A",
+				Timestamp: 1,
+			},
+			Arrival: &flightpath.ScheduleDetail{
+				City:      "Z",
+				Timestamp: 10,
+			},
+		}
+
+		It("should save flight schedule in the store", func() {
+			_ = dao.ScheduleModel.Put(schedule)
+			val, _ := dao.ScheduleModel.Get(schedule.ID)
+			Expect(val).ShouldNot(BeNil())
+			Expect(val.ID).To(Equal(schedule.ID))
+			Expect(val.Departure.City).To(Equal("A"))
+			Expect(val.Arrival.Timestamp).To(Equal(int64(10)))
+		})
+
+		It("should list flight schedules from the store", func() {
+			_ = dao.ScheduleModel.Put(schedule)
+			val, _ := dao.ScheduleModel.List()
+			Expect(val).ShouldNot(BeEmpty())
+
+			found := false
+			for _, s := range val {
+				if s.ID == schedule.ID {
+					found = true
+				}
+			}
+			Expect(found).To(BeTrue())
+		})
+
+		It("should delete flight schedule from the store", func() {
+			_ = dao.ScheduleModel.Put(schedule)
+			deleted, _ := dao.ScheduleModel.Delete(schedule.ID)
+			Expect(deleted).To(BeTrue())
+			val, err := dao.ScheduleModel.Get(schedule.ID)
+			Expect(err).Should(BeNil())
+			Expect(val).Should(BeNil())
+			deleted, _ = dao.ScheduleModel.Delete(schedule.ID)
+			Expect(deleted).To(BeFalse())
+		})
+
+		It("should update flight schedule only if it is in the store", func() {
+			_, _ = dao.ScheduleModel.Delete(schedule.ID)
+			updated, err := dao.ScheduleModel.PutIfPresent(schedule)
+			Expect(err).Should(BeNil())
+			Expect(updated).To(BeFalse())
+			val, _ := dao.ScheduleModel.Get(schedule.ID)
+			Expect(val).Should(BeNil())
+
+			_ = dao.ScheduleModel.Put(schedule)
+			updatedSchedule := *schedule
+			updatedSchedule.Arrival = &flightpath.ScheduleDetail{City: "Z", Timestamp: 5}
+			updated, err = dao.ScheduleModel.PutIfPresent(&updatedSchedule)
+			Expect(err).Should(BeNil())
+			Expect(updated).To(BeTrue())
+			val, _ = dao.ScheduleModel.Get(schedule.ID)
+			Expect(val.Arrival.Timestamp).To(Equal(int64(5)))
+			_, _ = dao.ScheduleModel.Delete(schedule.ID)
+		})
+
+		It("should change flight schedule and version of the store in a single call of the store", func() {
+			store := &writeCountingCache{Cache: cache.NewMemory(0)}
+			model := newScheduleModel(store)
+
+			Expect(model.Put(schedule)).Should(BeNil())
+			updated, _ := model.PutIfPresent(schedule)
+			Expect(updated).To(BeTrue())
+			deleted, _ := model.Delete(schedule.ID)
+			Expect(deleted).To(BeTrue())
+
+			Expect(store.writes).To(Equal(3))
+			version, err := model.Version()
+			Expect(err).Should(BeNil())
+			Expect(version).To(Equal(int64(3)))
+		})
+
+		It("should list flight schedules again only once the version of the store changes", func() {
+			_ = dao.ScheduleModel.Put(schedule)
+			val, version, err := dao.ScheduleModel.ListWithVersion()
+			Expect(err).Should(BeNil())
+			Expect(val).ShouldNot(BeEmpty())
+			sameVal, sameVersion, _ := dao.ScheduleModel.ListWithVersion()
+			Expect(sameVersion).To(Equal(version))
+			Expect(sameVal[0]).To(BeIdenticalTo(val[0]))
+
+			_, _ = dao.ScheduleModel.Delete(schedule.ID)
+			_, _ = dao.ScheduleModel.Delete(schedule.ID)
+			newVal, newVersion, _ := dao.ScheduleModel.ListWithVersion()
+			Expect(newVersion).To(Equal(version + 1))
+			for _, s := range newVal {
+				Expect(s.ID).ShouldNot(Equal(schedule.ID))
+			}
+		})
+	})
+
+	Context("##schedulestore", func() {
+		schedule := &flightpath.FlightDetail{
+			ID: "test-flight-456",
+			Departure: &flightpath.ScheduleDetail{
+				City:      "A",
+				Timestamp: 1,
+			},
+			Arrival: &flightpath.ScheduleDetail{
+				City:      "Z",
+				Timestamp: 10,
+			},
+		}
+
+		It("should keep flight schedules in the configured cache backend without redis", func() {
+			cacheBackend, redisURL := constants.Env.CacheBackend, constants.Env.RedisURL
+			defer func() {
+				constants.Env.CacheBackend, constants.Env.RedisURL = cacheBackend, redisURL
+			}()
+			// nothing listens on the port, so any use of redis fails
+			constants.Env.RedisURL = "localhost:1"
+
+			constants.Env.CacheBackend = literals.MemoryCache
+			dao, err := NewDao()
+			Expect(err).Should(BeNil())
+			_, isRedis := dao.Cache.(*cache.Breaker)
+			Expect(isRedis).To(BeFalse())
+			Expect(dao.ScheduleModel.Put(schedule)).Should(BeNil())
+			schedules, err := dao.ScheduleModel.List()
+			Expect(err).Should(BeNil())
+			Expect(schedules).Should(ContainElement(schedule))
+			_ = dao.Close()
+
+			constants.Env.CacheBackend = literals.NoCache
+			dao, err = NewDao()
+			Expect(err).Should(BeNil())
+			_, isRedis = dao.Cache.(*cache.Breaker)
+			Expect(isRedis).To(BeFalse())
+			Expect(dao.ScheduleModel.Put(schedule)).To(Equal(cache.ErrUnavailable))
+			_ = dao.Close()
+		})
+
+		It("should throw error instead of losing flight schedule if the store cannot keep it", func() {
+			dao := NewDaoWithCache(cache.NewNoop())
+			Expect(dao.ScheduleModel.Put(schedule)).To(Equal(cache.ErrUnavailable))
+		})
+	})
+})
+
+// writeCountingCache is a cache which counts the calls changing fields of its hashes
+type writeCountingCache struct {
+	cache.Cache
+	writes int
+}
+
+// PutField puts value of the field and counts the call
+func (w *writeCountingCache) PutField(key, field, value string) error {
+	w.writes++
+	return w.Cache.PutField(key, field, value)
+}
+
+// PutFieldIfPresent puts value of the field if it is present and counts the call
+func (w *writeCountingCache) PutFieldIfPresent(key, field, value string) (bool, error) {
+	w.writes++
+	return w.Cache.PutFieldIfPresent(key, field, value)
+}
+
+// DeleteField deletes the field and counts the call
+func (w *writeCountingCache) DeleteField(key, field string) (bool, error) {
+	w.writes++
+	return w.Cache.DeleteField(key, field)
+}
@@ -3,19 +3,76 @@ package api
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/somprabhsharma/the-lazy-traveler/handlers/flightpath"
+	"github.com/somprabhsharma/the-lazy-traveler/handlers/schedule"
+	"github.com/somprabhsharma/the-lazy-traveler/handlers/status"
+	"github.com/somprabhsharma/the-lazy-traveler/middlewares"
 	"github.com/somprabhsharma/the-lazy-traveler/models"
 )
 
 const (
 	// BaseURL .
 	BaseURL = "/the-lazy-traveler/api/1.0"
+	// BaseURLV2 .
+	BaseURLV2 = "/the-lazy-traveler/api/2.0"
 )
 
-//Register function registers the APIs to router
-func Register(router *gin.Engine, dao *models.Dao) {
-	// initialize flight path handler
-	flightPathHandler := flightpath.NewHandler(dao)
+// handlers are the handlers shared by all the api versions, so that every version uses same controllers
+type handlers struct {
+	flightPath *flightpath.Handler
+	schedule   *schedule.Handler
+	status     *status.Handler
+}
+
+// version is a set of api routes registered under its base url
+type version struct {
+	baseURL string
+	// successorURL is base url of the version replacing this version, empty if version is not deprecated
+	successorURL string
+	register     func(group *gin.RouterGroup, h *handlers)
+}
+
+// versions are all the api versions served side by side
+var versions = []version{
+	{
+		baseURL:      BaseURL,
+		successorURL: BaseURLV2,
+		register:     registerV1,
+	},
+	{
+		baseURL:  BaseURLV2,
+		register: registerV2,
+	},
+}
+
+// Register function registers the APIs of all the versions to router along with health and readiness APIs
+// status handler is returned, so that it can be told when the server starts shutting down
+func Register(router *gin.Engine, dao *models.Dao) *status.Handler {
+	// initialize handlers
+	h := &handlers{
+		flightPath: flightpath.NewHandler(dao),
+		schedule:   schedule.NewHandler(dao),
+		status:     status.NewHandler(dao),
+	}
+
+	for _, v := range versions {
+		group := router.Group(v.baseURL)
+		if v.successorURL != "" {
+			group.Use(middlewares.Deprecate(v.successorURL))
+		}
+		v.register(group, h)
+	}
+
+	// health and readiness apis are used by the orchestration, hence they are not versioned
+	router.GET("/healthz", h.status.Healthz)
+	router.GET("/readyz", h.status.Readyz)
+	return h.status
+}
 
-	lazyJackRoutes := router.Group(BaseURL + "/lazy_jack")
-	lazyJackRoutes.POST("", flightPathHandler.ValidateLazyJackRequest, flightPathHandler.FindShortestFlightPath)
+// registerScheduleRoutes registers flight schedule apis, their contract is same in all the versions
+func registerScheduleRoutes(group *gin.RouterGroup, h *handlers) {
+	scheduleRoutes := group.Group("/schedules")
+	scheduleRoutes.POST("", h.schedule.ValidateScheduleRequest, h.schedule.CreateSchedule)
+	scheduleRoutes.GET("", h.schedule.ListSchedules)
+	scheduleRoutes.PUT("/:id", h.schedule.ValidateScheduleRequest, h.schedule.UpdateSchedule)
+	scheduleRoutes.DELETE("/:id", h.schedule.DeleteSchedule)
 }
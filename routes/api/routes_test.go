@@ -0,0 +1,268 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/somprabhsharma/the-lazy-traveler/middlewares"
+	"github.com/somprabhsharma/the-lazy-traveler/models"
+	"github.com/somprabhsharma/the-lazy-traveler/models/cache"
+	"github.com/somprabhsharma/the-lazy-traveler/models/catalog"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/server"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRoutes(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "The Lazy Traveler Suite")
+}
+
+var _ = Describe("routes", func() {
+	Context("##api", func() {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(middlewares.HandleErrors)
+		dao := models.NewDaoWithCache(cache.NewMemory(0))
+		dao.CityCatalog, _ = catalog.Load("../../data/cities.csv")
+		Register(router, dao)
+
+		body := []byte(`{
+			"schedules": [
+				{"departure": {"city": "A", "timestamp": 1}, "arrival": {"city": "B", "timestamp": 3}, "flight_number": "LT1"},
+				{"departure": {"city": "B", "timestamp": 5}, "arrival": {"city": "Z", "timestamp": 8}, "flight_number": "LT2"}
+			],
+			"trip_plan": {"start_city": "A", "end_city": "Z"}
+		}`)
+
+		post := func(url string, body []byte) (*httptest.ResponseRecorder, map[string]interface{}) {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+			request.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(recorder, request)
+
+			response := make(map[string]interface{})
+			_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+			return recorder, response
+		}
+
+		It("should return flat flight plan on version 1.0 with deprecation headers", func() {
+			recorder, response := post(BaseURL+"/lazy_jack", body)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Deprecation")).To(Equal("true"))
+			Expect(recorder.Header().Get("Link")).To(Equal("<" + BaseURLV2 + ">; rel=\"successor-version\""))
+
+			Expect(response).To(HaveKey("flight_plan"))
+			flightPlan := response["flight_plan"].([]interface{})
+			Expect(len(flightPlan)).To(Equal(4))
+			Expect(flightPlan[0]).To(Equal(map[string]interface{}{"city": "A", "timestamp": float64(1)}))
+			Expect(flightPlan[1]).To(Equal(map[string]interface{}{"city": "B", "timestamp": float64(3), "flight": map[string]interface{}{"flight_number": "LT1"}}))
+			Expect(flightPlan[2]).To(Equal(map[string]interface{}{"city": "B", "timestamp": float64(5)}))
+			Expect(flightPlan[3]).To(Equal(map[string]interface{}{"city": "Z", "timestamp": float64(8), "flight": map[string]interface{}{"flight_number": "LT2"}}))
+		})
+
+		It("should return errors on version 1.0 with deprecation headers", func() {
+			recorder, response := post(BaseURL+"/lazy_jack", []byte(`{"schedules": []}`))
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Header().Get("Deprecation")).To(Equal("true"))
+			Expect(response["code"]).To(Equal(float64(101)))
+		})
+
+		It("should return itineraries with legs on version 2.0 without deprecation headers", func() {
+			recorder, response := post(BaseURLV2+"/lazy_jack", body)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Deprecation")).To(BeEmpty())
+
+			Expect(response).To(HaveKey("itineraries"))
+			itineraries := response["itineraries"].([]interface{})
+			Expect(len(itineraries)).To(Equal(1))
+
+			itinerary := itineraries[0].(map[string]interface{})
+			Expect(itinerary["total_duration"]).To(Equal(float64(7)))
+			Expect(itinerary["in_air_time"]).To(Equal(float64(5)))
+			Expect(itinerary["layover_time"]).To(Equal(float64(2)))
+			Expect(itinerary["stops"]).To(Equal(float64(1)))
+
+			legs := itinerary["legs"].([]interface{})
+			Expect(len(legs)).To(Equal(2))
+			Expect(legs[0]).To(Equal(map[string]interface{}{
+				"departure":       map[string]interface{}{"city": "A", "timestamp": float64(1)},
+				"arrival":         map[string]interface{}{"city": "B", "timestamp": float64(3)},
+				"flight":          map[string]interface{}{"flight_number": "LT1"},
+				"flight_duration": float64(2),
+				"layover":         float64(2),
+			}))
+		})
+
+		It("should return same errors on version 2.0", func() {
+			recorder, response := post(BaseURLV2+"/lazy_jack", []byte(`{"schedules": [], "trip_plan": {"start_city": "A", "end_city": "A"}}`))
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(response["code"]).To(Equal(float64(103)))
+		})
+
+		It("should reject flight schedules without time or arriving before their departure", func() {
+			for _, schedules := range []string{
+				`[{"departure": {"city": "A"}, "arrival": {"city": "Z"}}]`,
+				`[{"departure": {"city": "A", "timestamp": 10}, "arrival": {"city": "Z", "timestamp": 5}}]`,
+			} {
+				recorder, response := post(BaseURLV2+"/lazy_jack", []byte(`{"schedules": `+schedules+`, "trip_plan": {"start_city": "A", "end_city": "Z"}}`))
+				Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+				Expect(response["code"]).To(Equal(float64(104)))
+			}
+		})
+
+		It("should normalize cities of the request against the city catalog", func() {
+			recorder, response := post(BaseURLV2+"/lazy_jack", []byte(`{
+				"schedules": [
+					{"departure": {"city": "kennedy", "timestamp": 1}, "arrival": {"city": "Heathrow", "timestamp": 8}},
+					{"departure": {"city": "jfk", "timestamp": 2}, "arrival": {"city": "Delhi", "timestamp": 20}}
+				],
+				"trip_plan": {"start_city": "new york", "end_city": " london "}
+			}`))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			itineraries := response["itineraries"].([]interface{})
+			legs := itineraries[0].(map[string]interface{})["legs"].([]interface{})
+			leg := legs[0].(map[string]interface{})
+			Expect(leg["departure"]).To(Equal(map[string]interface{}{"city": "JFK", "timestamp": float64(1)}))
+			Expect(leg["arrival"]).To(Equal(map[string]interface{}{"city": "LHR", "timestamp": float64(8)}))
+		})
+
+		It("should return explanation of the search along with itineraries on version 2.0 if asked", func() {
+			recorder, response := post(BaseURLV2+"/lazy_jack", []byte(`{
+				"schedules": [
+					{"departure": {"city": "A", "timestamp": 1}, "arrival": {"city": "Z", "timestamp": 3}},
+					{"departure": {"city": "A", "timestamp": 1}, "arrival": {"city": "Z", "timestamp": 8}}
+				],
+				"trip_plan": {"start_city": "A", "end_city": "Z"},
+				"explain": true
+			}`))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(len(response["itineraries"].([]interface{}))).To(Equal(1))
+
+			explanation := response["explanation"].(map[string]interface{})
+			Expect(explanation["engine"]).To(Equal("dijkstra"))
+			candidates := explanation["candidates"].([]interface{})
+			Expect(len(candidates)).To(Equal(2))
+			Expect(candidates[0].(map[string]interface{})["selected"]).To(Equal(true))
+			Expect(candidates[1].(map[string]interface{})["rejected_reason"]).To(Equal("longer duration than itinerary 1"))
+		})
+
+		It("should return result of every trip plan of the batch on version 2.0", func() {
+			recorder, response := post(BaseURLV2+"/lazy_jack/batch", []byte(`{
+				"schedules": [
+					{"departure": {"city": "A", "timestamp": 1}, "arrival": {"city": "B", "timestamp": 3}},
+					{"departure": {"city": "B", "timestamp": 5}, "arrival": {"city": "Z", "timestamp": 8}}
+				],
+				"trip_plans": [{"start_city": "A", "end_city": "Z"}, {"start_city": "Z", "end_city": "A"}, {"start_city": "B", "end_city": "B"}]
+			}`))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			results := response["results"].([]interface{})
+			Expect(len(results)).To(Equal(3))
+			Expect(results[0].(map[string]interface{})["trip_plan"]).To(Equal(map[string]interface{}{"start_city": "A", "end_city": "Z"}))
+			Expect(len(results[0].(map[string]interface{})["itineraries"].([]interface{}))).To(Equal(1))
+			Expect(results[1].(map[string]interface{})["error"].(map[string]interface{})["code"]).To(Equal(float64(102)))
+			Expect(results[2].(map[string]interface{})["error"].(map[string]interface{})["code"]).To(Equal(float64(103)))
+		})
+
+		It("should reject batch without trip plans", func() {
+			recorder, response := post(BaseURLV2+"/lazy_jack/batch", body)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(response["code"]).To(Equal(float64(101)))
+		})
+
+		It("should return state of the circuit breaker of the cache on version 2.0", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest(http.MethodGet, BaseURLV2+"/status/cache", nil)
+			router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			response := make(map[string]interface{})
+			_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+			Expect(response["cache"].(map[string]interface{})["state"]).To(Equal("closed"))
+		})
+
+		It("should return health and readiness of the service", func() {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest(http.MethodGet, "/healthz", nil)
+			router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"status": "alive"}`))
+
+			recorder = httptest.NewRecorder()
+			request, _ = http.NewRequest(http.MethodGet, "/readyz", nil)
+			router.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			response := make(map[string]interface{})
+			_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+			Expect(response["status"]).To(Equal("ready"))
+			components := response["components"].(map[string]interface{})
+			Expect(components).To(HaveKey("cache"))
+			Expect(components).To(HaveKey("circuit_breaker"))
+			Expect(components).To(HaveKey("timetable"))
+			Expect(components["cache"].(map[string]interface{})).To(HaveKey("latency_ms"))
+		})
+
+		It("should not be ready once the server starts shutting down", func() {
+			shutdownRouter := gin.New()
+			Register(shutdownRouter, dao).ShutDown()
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+			shutdownRouter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"status": "shutting_down"}`))
+		})
+
+		It("should complete a slow search which is in flight while the server shuts down", func() {
+			started, release := make(chan struct{}, 1), make(chan struct{})
+			slowRouter := gin.New()
+			slowRouter.Use(middlewares.HandleErrors)
+			slowRouter.Use(func(c *gin.Context) {
+				started <- struct{}{}
+				<-release
+			})
+			srv := server.New(":0", slowRouter, time.Minute, 0)
+			statusHandler := Register(slowRouter, dao)
+			srv.BeforeShutdown(statusHandler.ShutDown)
+
+			listener, _ := net.Listen("tcp", "127.0.0.1:0")
+			stop := make(chan os.Signal, 1)
+			served := make(chan error, 1)
+			go func() {
+				served <- srv.Serve(listener, stop)
+			}()
+
+			responded := make(chan *http.Response, 1)
+			go func() {
+				defer GinkgoRecover()
+				response, err := http.Post("http://"+listener.Addr().String()+BaseURLV2+"/lazy_jack", "application/json", bytes.NewReader(body))
+				Expect(err).Should(BeNil())
+				responded <- response
+			}()
+			Eventually(started).Should(Receive())
+
+			stop <- syscall.SIGTERM
+			Consistently(served, 100*time.Millisecond).ShouldNot(Receive())
+			close(release)
+
+			var response *http.Response
+			Eventually(responded).Should(Receive(&response))
+			defer response.Body.Close()
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			result := make(map[string]interface{})
+			_ = json.NewDecoder(response.Body).Decode(&result)
+			Expect(result["itineraries"]).To(HaveLen(1))
+			Eventually(served).Should(Receive(BeNil()))
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// registerV1 registers version 1.0 APIs, lazy jack returns flat flight plan
+func registerV1(group *gin.RouterGroup, h *handlers) {
+	lazyJackRoutes := group.Group("/lazy_jack")
+	lazyJackRoutes.POST("", h.flightPath.ValidateLazyJackRequest, h.flightPath.FindShortestFlightPath)
+
+	registerScheduleRoutes(group, h)
+}
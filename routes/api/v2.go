@@ -0,0 +1,16 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// registerV2 registers version 2.0 APIs, lazy jack returns itineraries with separate legs
+func registerV2(group *gin.RouterGroup, h *handlers) {
+	lazyJackRoutes := group.Group("/lazy_jack")
+	lazyJackRoutes.POST("", h.flightPath.ValidateLazyJackRequest, h.flightPath.FindItineraries)
+	lazyJackRoutes.POST("/batch", h.flightPath.ValidateBatchRequest, h.flightPath.FindBatchItineraries)
+
+	registerScheduleRoutes(group, h)
+
+	group.GET("/status/cache", h.status.CacheStatus)
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"github.com/somprabhsharma/the-lazy-traveler/utils/logger"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Server is an http server which stops gracefully, once it is asked to stop it keeps serving for the drain delay,
+// then it stops accepting connections and waits for the in flight requests to complete for up to the shutdown timeout before it returns
+type Server struct {
+	httpServer      *http.Server
+	shutdownTimeout time.Duration
+	drainDelay      time.Duration
+	beforeShutdown  []func()
+}
+
+// New creates a server which serves the handler at the address
+func New(addr string, handler http.Handler, shutdownTimeout, drainDelay time.Duration) *Server {
+	return &Server{
+		httpServer:      &http.Server{Addr: addr, Handler: handler},
+		shutdownTimeout: shutdownTimeout,
+		drainDelay:      drainDelay,
+	}
+}
+
+// BeforeShutdown registers a function which is called once the server is asked to stop, drain delay before connections are closed
+// e.g. to fail readiness of the service, so that load balancer notices it and stops sending requests to it within the drain delay
+func (s *Server) BeforeShutdown(f func()) {
+	s.beforeShutdown = append(s.beforeShutdown, f)
+}
+
+// ListenAndServe listens at the address of the server and serves requests until the process receives SIGINT or SIGTERM
+func (s *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	return s.Serve(listener, stop)
+}
+
+// Serve serves requests accepted by the listener until a signal is received on stop, then it shuts down gracefully
+// error is returned if server fails, or if in flight requests do not complete within the shutdown timeout
+func (s *Server) Serve(listener net.Listener, stop <-chan os.Signal) error {
+	served := make(chan error, 1)
+	go func() {
+		served <- s.httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case sig := <-stop:
+		logger.Info("Server", "Shutting down server, in flight requests are drained", sig.String())
+	}
+
+	for _, f := range s.beforeShutdown {
+		f()
+	}
+
+	// requests keep being served until load balancer stops sending them, another signal stops waiting for it
+	if s.drainDelay > 0 {
+		logger.Info("Server", "Waiting for load balancer to stop sending requests before shutting down", s.drainDelay.String())
+		timer := time.NewTimer(s.drainDelay)
+		select {
+		case <-timer.C:
+		case <-stop:
+			timer.Stop()
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	err := s.httpServer.Shutdown(ctx)
+	if err != nil {
+		// requests which are still running are cut, so that the process does not wait for them forever
+		logger.Err("Server", "In flight requests did not complete within shutdown timeout", err, s.shutdownTimeout.String())
+		_ = s.httpServer.Close()
+		return err
+	}
+	logger.Info("Server", "Server is shut down", nil)
+	return nil
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestServer(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "The Lazy Traveler Suite")
+}
+
+var _ = Describe("utils", func() {
+	Context("##server", func() {
+		// slowHandler responds once it is released, so that requests are in flight while server shuts down
+		slowHandler := func(started chan<- struct{}, release <-chan struct{}) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				started <- struct{}{}
+				<-release
+				_, _ = w.Write([]byte("done"))
+			})
+		}
+
+		serve := func(server *Server) (string, chan<- os.Signal, <-chan error) {
+			listener, _ := net.Listen("tcp", "127.0.0.1:0")
+			stop := make(chan os.Signal, 1)
+			served := make(chan error, 1)
+			go func() {
+				served <- server.Serve(listener, stop)
+			}()
+			return "http://" + listener.Addr().String(), stop, served
+		}
+
+		It("should complete in flight requests and stop accepting new ones once it is asked to stop", func() {
+			started, release := make(chan struct{}, 1), make(chan struct{})
+			server := New(":0", slowHandler(started, release), time.Minute, 0)
+			shutdownStarted := make(chan struct{})
+			server.BeforeShutdown(func() { close(shutdownStarted) })
+			url, stop, served := serve(server)
+
+			type result struct {
+				body string
+				err  error
+			}
+			responded := make(chan result, 1)
+			go func() {
+				response, err := http.Get(url)
+				if err != nil {
+					responded <- result{err: err}
+					return
+				}
+				defer response.Body.Close()
+				body, _ := ioutil.ReadAll(response.Body)
+				responded <- result{body: string(body)}
+			}()
+			Eventually(started).Should(Receive())
+
+			stop <- syscall.SIGTERM
+			Eventually(shutdownStarted).Should(BeClosed())
+			Eventually(func() error {
+				_, err := http.Get(url)
+				return err
+			}).ShouldNot(BeNil())
+			Consistently(served, 100*time.Millisecond).ShouldNot(Receive())
+
+			close(release)
+			var res result
+			Eventually(responded).Should(Receive(&res))
+			Expect(res.err).Should(BeNil())
+			Expect(res.body).To(Equal("done"))
+			Eventually(served).Should(Receive(BeNil()))
+		})
+
+		It("should keep serving for the drain delay after it is marked as shutting down and then shut down", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("done"))
+			})
+			server := New(":0", handler, time.Minute, 300*time.Millisecond)
+			shutdownStarted := make(chan time.Time, 1)
+			server.BeforeShutdown(func() { shutdownStarted <- time.Now() })
+			url, stop, served := serve(server)
+
+			stop <- syscall.SIGTERM
+			var startedAt time.Time
+			Eventually(shutdownStarted).Should(Receive(&startedAt))
+
+			// new requests are served during the drain delay, as load balancer can still send them
+			response, err := http.Get(url)
+			Expect(err).Should(BeNil())
+			_ = response.Body.Close()
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			Expect(served).ShouldNot(Receive())
+
+			Eventually(served).Should(Receive(BeNil()))
+			Expect(time.Since(startedAt)).To(BeNumerically(">=", 300*time.Millisecond))
+			_, err = http.Get(url)
+			Expect(err).ShouldNot(BeNil())
+		})
+
+		It("should stop waiting for the drain delay once it is asked to stop again", func() {
+			server := New(":0", http.NotFoundHandler(), time.Minute, time.Hour)
+			_, stop, served := serve(server)
+
+			stop <- syscall.SIGTERM
+			Consistently(served, 100*time.Millisecond).ShouldNot(Receive())
+			stop <- syscall.SIGTERM
+			Eventually(served).Should(Receive(BeNil()))
+		})
+
+		It("should cut in flight requests which do not complete within shutdown timeout", func() {
+			started, release := make(chan struct{}, 1), make(chan struct{})
+			defer close(release)
+			url, stop, served := serve(New(":0", slowHandler(started, release), 50*time.Millisecond, 0))
+
+			go func() {
+				_, _ = http.Get(url)
+			}()
+			Eventually(started).Should(Receive())
+
+			stop <- syscall.SIGINT
+			Eventually(served).Should(Receive(Equal(context.DeadlineExceeded)))
+		})
+	})
+})
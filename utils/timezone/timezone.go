@@ -0,0 +1,71 @@
+package timezone
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// localTimeLayout is ISO-8601 date time without offset
+	localTimeLayout = "2006-01-02T15:04:05"
+	// zone transitions happen at most once a day, hence offsets are looked up within a day around the local time
+	transitionWindow = 26 * time.Hour
+)
+
+var (
+	// ErrNonExistentLocalTime is returned when local time falls in a gap i.e. clocks are moved forward
+	ErrNonExistentLocalTime = errors.New("local time does not exist in the time zone")
+	// ErrAmbiguousLocalTime is returned when local time falls in an overlap i.e. clocks are moved backward
+	ErrAmbiguousLocalTime = errors.New("local time is ambiguous in the time zone")
+)
+
+// ParseTime parses ISO-8601 date time with offset e.g. 2019-03-10T08:30:00-05:00
+func ParseTime(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339, value)
+}
+
+// ParseLocalTime parses ISO-8601 date time without offset e.g. 2019-03-10T08:30:00 in the given time zone
+// it returns error if the local time is skipped or repeated in the time zone because of daylight saving time
+func ParseLocalTime(value string, location *time.Location) (time.Time, error) {
+	wallClock, err := time.Parse(localTimeLayout, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// collect all the offsets the time zone can have around the local time
+	offsets := make(map[int]bool)
+	for _, t := range []time.Time{wallClock.Add(-transitionWindow), wallClock, wallClock.Add(transitionWindow)} {
+		_, offset := t.In(location).Zone()
+		offsets[offset] = true
+	}
+
+	// an instant is a valid interpretation of the local time if it shows the same wall clock in the time zone
+	instants := make([]time.Time, 0, len(offsets))
+	for offset := range offsets {
+		instant := wallClock.Add(-time.Duration(offset) * time.Second).In(location)
+		if isSameWallClock(instant, wallClock) {
+			instants = append(instants, instant)
+		}
+	}
+
+	if len(instants) == 0 {
+		return time.Time{}, ErrNonExistentLocalTime
+	}
+	if len(instants) > 1 {
+		return time.Time{}, ErrAmbiguousLocalTime
+	}
+	return instants[0], nil
+}
+
+// FormatTime formats time as ISO-8601 date time with offset of the given time zone
+func FormatTime(t time.Time, location *time.Location) string {
+	return t.In(location).Format(time.RFC3339)
+}
+
+// isSameWallClock tells if t shows same date & time as wallClock irrespective of their time zones
+func isSameWallClock(t, wallClock time.Time) bool {
+	year, month, day := t.Date()
+	wallYear, wallMonth, wallDay := wallClock.Date()
+	return year == wallYear && month == wallMonth && day == wallDay &&
+		t.Hour() == wallClock.Hour() && t.Minute() == wallClock.Minute() && t.Second() == wallClock.Second()
+}
@@ -0,0 +1,61 @@
+package timezone
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"testing"
+	"time"
+)
+
+func TestTimezone(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "The Lazy Traveler Suite")
+}
+
+var _ = Describe("utils", func() {
+	Context("##timezone", func() {
+		newYork, _ := time.LoadLocation("America/New_York")
+
+		It("should parse time with offset", func() {
+			t, err := ParseTime("2019-06-01T18:00:00-04:00")
+			Expect(err).Should(BeNil())
+			Expect(t.Unix()).To(Equal(int64(1559426400)))
+		})
+
+		It("should throw error if time does not have offset", func() {
+			_, err := ParseTime("2019-06-01T18:00:00")
+			Expect(err).ShouldNot(BeNil())
+		})
+
+		It("should parse local time in the time zone", func() {
+			t, err := ParseLocalTime("2019-06-01T18:00:00", newYork)
+			Expect(err).Should(BeNil())
+			Expect(t.Unix()).To(Equal(int64(1559426400)))
+		})
+
+		It("should parse local time right before and after the daylight saving time transition", func() {
+			t, err := ParseLocalTime("2019-03-10T01:59:59", newYork)
+			Expect(err).Should(BeNil())
+			Expect(FormatTime(t, time.UTC)).To(Equal("2019-03-10T06:59:59Z"))
+			t, err = ParseLocalTime("2019-03-10T03:00:00", newYork)
+			Expect(err).Should(BeNil())
+			Expect(FormatTime(t, time.UTC)).To(Equal("2019-03-10T07:00:00Z"))
+		})
+
+		It("should throw error if local time falls in the gap when clocks move forward", func() {
+			_, err := ParseLocalTime("2019-03-10T02:30:00", newYork)
+			Expect(err).To(Equal(ErrNonExistentLocalTime))
+		})
+
+		It("should throw error if local time falls in the overlap when clocks move backward", func() {
+			_, err := ParseLocalTime("2019-11-03T01:30:00", newYork)
+			Expect(err).To(Equal(ErrAmbiguousLocalTime))
+		})
+
+		It("should format time in the time zone", func() {
+			t := time.Unix(1559426400, 0)
+			Expect(FormatTime(t, newYork)).To(Equal("2019-06-01T18:00:00-04:00"))
+			Expect(FormatTime(t, time.UTC)).To(Equal("2019-06-01T22:00:00Z"))
+		})
+	})
+})